@@ -0,0 +1,155 @@
+// Command sigdb-import loads signature dumps straight into the signature database.
+//
+//	sigdb-import -format 4byte -type event events-*.json
+//	curl https://api.openchain.xyz/signature-database/v1/export | sigdb-import -
+//	curl 'https://api.openchain.xyz/signature-database/v1/export?format=ndjson' | sigdb-import -format ndjson -
+//
+// Our csv and ndjson exports are checked against their trailer, so one which was cut short fails rather than looking
+// like it was imported in full.
+//
+// Database settings are read from the same environment variables as signature-database-srv.
+package main
+
+import (
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/config"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/bulk"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/client"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/database"
+	log "github.com/sirupsen/logrus"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	format    = flag.String("format", string(bulk.FormatText), "format of the input files")
+	typ       = flag.String("type", string(client.SignatureTypeFunction), "signature type, for formats which don't include it")
+	batchSize = flag.Int("batch", 10000, "number of signatures to save per batch")
+	source    = flag.String("source", string(client.SignatureSourceImport), "source to record for imported signatures")
+	submitter = flag.String("submitter", "sigdb-import", "submitter to record for imported signatures")
+	invalid   = flag.String("invalid", "", "write invalid entries to this file instead of logging them")
+)
+
+// Config holds the database settings, read from the same environment variables as signature-database-srv
+type Config struct {
+	DatabaseHost     string `def:"127.0.0.1" env:"DB_HOST"`
+	DatabasePort     int    `def:"5432" env:"DB_PORT"`
+	DatabaseName     string `def:"postgres" env:"DB_NAME"`
+	DatabaseUser     string `def:"ethereum" env:"DB_USER"`
+	DatabasePassword string `def:"ethereum" env:"DB_PASS"`
+}
+
+func open(path string) (io.ReadCloser, error) {
+	var f io.ReadCloser = os.Stdin
+	if path != "-" {
+		var err error
+		if f, err = os.Open(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if !strings.HasSuffix(path, ".gz") {
+		return f, nil
+	}
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{gz, f}, nil
+}
+
+func run() error {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] file... (- for stdin, .gz files are decompressed)\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if !client.SignatureType(*typ).Valid() {
+		return fmt.Errorf("invalid signature type: %s", *typ)
+	}
+	if !client.SignatureSource(*source).Valid() {
+		return fmt.Errorf("invalid source: %s", *source)
+	}
+
+	var cfg Config
+	if err := config.LoadConfig(&cfg); err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	db, err := database.New(cfg.DatabaseHost, cfg.DatabasePort, cfg.DatabaseName, cfg.DatabaseUser, cfg.DatabasePassword)
+	if err != nil {
+		return fmt.Errorf("failed to create database: %w", err)
+	}
+
+	importer := bulk.NewImporter(db, &database.SignatureMetadata{
+		Source:    client.SignatureSource(*source),
+		Submitter: *submitter,
+	}, *batchSize)
+
+	if *invalid != "" {
+		f, err := os.Create(*invalid)
+		if err != nil {
+			return fmt.Errorf("failed to create invalid entries file: %w", err)
+		}
+		defer f.Close()
+
+		importer.OnInvalid = func(err error) {
+			fmt.Fprintln(f, err)
+		}
+	}
+
+	start := time.Now()
+	importer.OnProgress = func(stats bulk.Stats) {
+		log.WithFields(log.Fields{
+			"read":       stats.Read,
+			"imported":   stats.Imported,
+			"duplicated": stats.Duplicated,
+			"invalid":    stats.Invalid,
+			"rate":       fmt.Sprintf("%.0f/s", float64(stats.Read)/time.Since(start).Seconds()),
+		}).Infof("importing")
+	}
+
+	for _, path := range flag.Args() {
+		f, err := open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", path, err)
+		}
+
+		r, err := bulk.NewReader(f, bulk.Format(*format), client.SignatureType(*typ))
+		if err != nil {
+			f.Close()
+			return err
+		}
+
+		log.WithField("file", path).Infof("importing file")
+		_, err = importer.Import(r)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("failed to import %s: %w", path, err)
+		}
+	}
+
+	log.WithField("took", time.Since(start)).Infof("import finished")
+
+	return nil
+}
+
+func main() {
+	if err := run(); err != nil {
+		log.WithError(err).Fatalf("failed to import")
+	}
+}
@@ -22,6 +22,10 @@ func run() error {
 		return fmt.Errorf("failed to start service: %w", err)
 	}
 
+	if err := a.Wait(); err != nil {
+		return fmt.Errorf("service stopped: %w", err)
+	}
+
 	return nil
 }
 
@@ -30,5 +34,4 @@ func main() {
 	if err := run(); err != nil {
 		log.WithError(err).Fatalf("failed to run service")
 	}
-	<-make(chan struct{})
 }
@@ -37,6 +37,7 @@ type ImportResponseDetails = {
     imported: Record<string, string>;
     duplicated: Record<string, string>;
     invalid: string[];
+    rewritten?: Record<string, string>;
 };
 
 type ImportResponse = {
@@ -147,11 +148,17 @@ function processImport(results: ImportResponse): [React.ReactElement, string] {
     let impEvents = Object.entries(results['event']['imported']).length;
     let dupFunctions = Object.entries(results['function']['duplicated']).length;
     let dupEvents = Object.entries(results['event']['duplicated']).length;
+    let rewritten = Object.values(results).reduce(
+        (count, details) => count + Object.keys(details['rewritten'] || {}).length,
+        0,
+    );
+
+    let message = `Imported ${impFunctions} functions and ${impEvents} events! Skipped ${dupFunctions} functions and ${dupEvents} events.`;
+    if (rewritten > 0) {
+        message += ` Normalized ${rewritten} declarations to their canonical signatures.`;
+    }
 
-    return [
-        renderedImportResults,
-        `Imported ${impFunctions} functions and ${impEvents} events! Skipped ${dupFunctions} functions and ${dupEvents} events.`,
-    ];
+    return [renderedImportResults, message];
 }
 
 export default function Import() {
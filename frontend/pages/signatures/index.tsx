@@ -28,8 +28,30 @@ type SignatureData = {
 type SignaturesResponse = {
     function: Record<string, SignatureData[]>;
     event: Record<string, SignatureData[]>;
+    error: Record<string, SignatureData[]>;
 };
 
+type SearchResult = SignatureData & {
+    hash: string;
+};
+
+type SearchResponse = {
+    results: Record<keyof SignaturesResponse, SearchResult[]>;
+    next_cursor?: string;
+};
+
+function searchToSignaturesResponse(search: SearchResponse): SignaturesResponse {
+    let response = { function: {}, event: {}, error: {} } as SignaturesResponse;
+    for (let [sigType, results] of Object.entries(search.results)) {
+        let sigResultsMap = response[sigType as keyof SignaturesResponse];
+        for (let result of results) {
+            sigResultsMap[result.hash] = sigResultsMap[result.hash] || [];
+            sigResultsMap[result.hash].push({ name: result.name, filtered: result.filtered });
+        }
+    }
+    return response;
+}
+
 function constructSearchParams(query: string): [string, string, 'lookup_function' | 'lookup_event' | 'search'] {
     const hexRe = /^[0-9A-Fa-f]*$/;
 
@@ -192,7 +214,8 @@ export default function Index() {
             dismissed: true,
         }));
         const [method, params, searchType] = constructSearchParams(queryTrimmed);
-        fetch(`${apiEndpoint()}/v1/${method}?${params}`)
+        const version = searchType === 'search' ? 'v2' : 'v1';
+        fetch(`${apiEndpoint()}/${version}/${method}?${params}`)
             .then((res) => res.json())
             .then((json) => {
                 if (json['ok'] === false) {
@@ -200,7 +223,11 @@ export default function Index() {
                 }
 
                 setIsSearching(false);
-                setSearchResults(processSearch(searchType, json['result'] as SignaturesResponse));
+                const result =
+                    searchType === 'search'
+                        ? searchToSignaturesResponse(json['result'] as SearchResponse)
+                        : (json['result'] as SignaturesResponse);
+                setSearchResults(processSearch(searchType, result));
             })
             .catch((e) => {
                 setIsSearching(false);
@@ -0,0 +1,291 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Role string
+
+const (
+	RoleNone     Role = "none"
+	RoleReadOnly Role = "read_only"
+	RoleImporter Role = "importer"
+	// RoleObserver may also vouch for signatures having been seen on-chain or in verified source, which ranks them above
+	// their collisions
+	RoleObserver Role = "observer"
+	RoleAdmin    Role = "admin"
+)
+
+var roleLevels = map[Role]int{
+	RoleNone:     0,
+	RoleReadOnly: 1,
+	RoleImporter: 2,
+	RoleObserver: 3,
+	RoleAdmin:    4,
+}
+
+func (r Role) Valid() bool {
+	_, ok := roleLevels[r]
+	return ok
+}
+
+// Includes returns whether a key with this role may do everything a key with the other role may do
+func (r Role) Includes(other Role) bool {
+	return roleLevels[r] >= roleLevels[other]
+}
+
+type Key struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Role Role   `json:"role"`
+	// SecretHash is the sha256 of the secret half of the key, which bearer tokens are checked against
+	SecretHash []byte `json:"-"`
+	// SigningKey is what signed requests are keyed by, derived from the secret with SigningKey. It's nil for keys
+	// which can only be presented as bearer tokens
+	SigningKey []byte     `json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+type KeyStore interface {
+	// LookupKey returns the key with the given id, or nil if there is no such key
+	LookupKey(ctx context.Context, id string) (*Key, error)
+}
+
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+)
+
+const (
+	HeaderKeyID     = "X-Api-Key"
+	HeaderTimestamp = "X-Timestamp"
+	HeaderSignature = "X-Signature"
+
+	maxSignatureAge = 5 * time.Minute
+)
+
+type Authenticator struct {
+	stores    []KeyStore
+	anonymous Role
+	onFailure func(w http.ResponseWriter, status int, err error)
+}
+
+type Option func(a *Authenticator)
+
+func WithKeyStore(store KeyStore) Option {
+	return func(a *Authenticator) {
+		a.stores = append(a.stores, store)
+	}
+}
+
+// WithAnonymousRole sets the role given to requests which don't present a key. Defaults to RoleNone.
+func WithAnonymousRole(role Role) Option {
+	return func(a *Authenticator) {
+		a.anonymous = role
+	}
+}
+
+// WithFailureHandler sets how rejected requests are responded to, so that services can keep their own error format
+func WithFailureHandler(fn func(w http.ResponseWriter, status int, err error)) Option {
+	return func(a *Authenticator) {
+		a.onFailure = fn
+	}
+}
+
+func New(apply ...Option) *Authenticator {
+	a := &Authenticator{
+		anonymous: RoleNone,
+		onFailure: func(w http.ResponseWriter, status int, err error) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(map[string]any{
+				"ok":    false,
+				"error": err.Error(),
+			})
+		},
+	}
+	for _, fn := range apply {
+		fn(a)
+	}
+	return a
+}
+
+type contextKey struct{}
+
+// KeyFromContext returns the key which authenticated the request, or nil if the request was anonymous
+func KeyFromContext(ctx context.Context) *Key {
+	key, _ := ctx.Value(contextKey{}).(*Key)
+	return key
+}
+
+// KeyName returns the name of the key which authenticated the request, or "anonymous"
+func KeyName(ctx context.Context) string {
+	if key := KeyFromContext(ctx); key != nil {
+		return key.Name
+	}
+	return "anonymous"
+}
+
+// Require only lets through requests whose key, or the anonymous role if no key was presented, includes the role.
+// Keys may be presented as a bearer token of the form "<id>.<secret>", or by signing the request with hmac. Signed
+// requests set X-Api-Key to the key id, X-Timestamp to the unix time, and X-Signature to the hex encoded
+// hmac-sha256 of "<timestamp>\n<method>\n<path and query>\n<body>" keyed by SigningKey(secret).
+func (a *Authenticator) Require(role Role, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key, err := a.authenticate(r)
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			a.onFailure(w, http.StatusRequestEntityTooLarge, err)
+			return
+		} else if err != nil {
+			a.onFailure(w, http.StatusUnauthorized, err)
+			return
+		}
+
+		ctx, err := a.Authorize(r.Context(), key, role)
+		if errors.Is(err, ErrForbidden) {
+			a.onFailure(w, http.StatusForbidden, err)
+			return
+		} else if err != nil {
+			a.onFailure(w, http.StatusUnauthorized, err)
+			return
+		}
+
+		next(w, r.WithContext(ctx))
+	}
+}
+
+// Authorize checks that the key, or the anonymous role if it's nil, includes the role, and returns a context which
+// KeyFromContext will find the key in. It's for transports other than http, which authenticate keys themselves.
+func (a *Authenticator) Authorize(ctx context.Context, key *Key, role Role) (context.Context, error) {
+	granted := a.anonymous
+	if key != nil {
+		granted = key.Role
+		ctx = context.WithValue(ctx, contextKey{}, key)
+	}
+
+	if !granted.Includes(role) {
+		if key == nil {
+			return nil, ErrUnauthorized
+		}
+		return nil, ErrForbidden
+	}
+
+	return ctx, nil
+}
+
+// AuthenticateToken returns the key for a bearer token of the form "<id>.<secret>"
+func (a *Authenticator) AuthenticateToken(ctx context.Context, token string) (*Key, error) {
+	id, secret, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+
+	key, err := a.lookupKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	secretHash := sha256.Sum256([]byte(secret))
+	if subtle.ConstantTimeCompare(secretHash[:], key.SecretHash) != 1 {
+		return nil, ErrUnauthorized
+	}
+
+	return key, nil
+}
+
+func (a *Authenticator) authenticate(r *http.Request) (*Key, error) {
+	if id := r.Header.Get(HeaderKeyID); id != "" {
+		return a.authenticateSignature(r, id)
+	}
+
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		return a.AuthenticateToken(r.Context(), strings.TrimPrefix(header, "Bearer "))
+	}
+
+	return nil, nil
+}
+
+func (a *Authenticator) authenticateSignature(r *http.Request, id string) (*Key, error) {
+	timestamp, err := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid timestamp", ErrUnauthorized)
+	}
+	if age := time.Since(time.Unix(timestamp, 0)); age > maxSignatureAge || age < -maxSignatureAge {
+		return nil, fmt.Errorf("%w: timestamp out of range", ErrUnauthorized)
+	}
+
+	signature, err := hex.DecodeString(r.Header.Get(HeaderSignature))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid signature", ErrUnauthorized)
+	}
+
+	key, err := a.lookupKey(r.Context(), id)
+	if err != nil {
+		return nil, err
+	}
+
+	var body []byte
+	if r.Body != nil {
+		body, err = io.ReadAll(r.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read body: %w", err)
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	if key.SigningKey == nil {
+		return nil, fmt.Errorf("%w: key can't sign requests", ErrUnauthorized)
+	}
+	if !hmac.Equal(signature, Sign(key.SigningKey, timestamp, r.Method, r.URL.RequestURI(), body)) {
+		return nil, fmt.Errorf("%w: invalid signature", ErrUnauthorized)
+	}
+
+	return key, nil
+}
+
+func (a *Authenticator) lookupKey(ctx context.Context, id string) (*Key, error) {
+	for _, store := range a.stores {
+		key, err := store.LookupKey(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lookup key: %w", err)
+		}
+		if key != nil {
+			if key.RevokedAt != nil {
+				return nil, ErrUnauthorized
+			}
+			return key, nil
+		}
+	}
+	return nil, ErrUnauthorized
+}
+
+// SigningKey derives the key requests are signed with from the secret half of a key. It's kept separate from the
+// SecretHash so that reading the stored hashes isn't enough to sign requests
+func SigningKey(secret string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("openchain request signing"))
+	return mac.Sum(nil)
+}
+
+// Sign computes the signature of a request, given the SigningKey of the secret
+func Sign(signingKey []byte, timestamp int64, method string, requestURI string, body []byte) []byte {
+	mac := hmac.New(sha256.New, signingKey)
+	fmt.Fprintf(mac, "%d\n%s\n%s\n", timestamp, method, requestURI)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Require(t *testing.T) {
+	importer, importerToken, err := GenerateKey("ci", RoleImporter)
+	assert.NoError(t, err)
+	admin, adminToken, err := GenerateKey("ops", RoleAdmin)
+	assert.NoError(t, err)
+
+	store, err := ParseStaticKeys([]string{
+		fmt.Sprintf("%s:%s:%s", importer.Name, importer.Role, importerToken),
+		fmt.Sprintf("%s:%s:%s", admin.Name, admin.Role, adminToken),
+	})
+	assert.NoError(t, err)
+
+	a := New(WithKeyStore(store), WithAnonymousRole(RoleReadOnly))
+
+	handler := func(role Role) http.HandlerFunc {
+		return a.Require(role, func(w http.ResponseWriter, r *http.Request) {
+			if key := KeyFromContext(r.Context()); key != nil {
+				io.WriteString(w, key.Name)
+			}
+			body, _ := io.ReadAll(r.Body)
+			w.Write(body)
+		})
+	}
+
+	for _, tc := range []struct {
+		name   string
+		role   Role
+		token  string
+		status int
+		body   string
+	}{
+		{"anonymous read", RoleReadOnly, "", http.StatusOK, ""},
+		{"anonymous import", RoleImporter, "", http.StatusUnauthorized, ""},
+		{"importer import", RoleImporter, importerToken, http.StatusOK, "ci"},
+		{"importer admin", RoleAdmin, importerToken, http.StatusForbidden, ""},
+		{"admin import", RoleImporter, adminToken, http.StatusOK, "ops"},
+		{"bad secret", RoleReadOnly, importer.ID + ".wrong", http.StatusUnauthorized, ""},
+		{"unknown key", RoleReadOnly, "unknown.secret", http.StatusUnauthorized, ""},
+	} {
+		req := httptest.NewRequest("GET", "/", nil)
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+		rec := httptest.NewRecorder()
+		handler(tc.role)(rec, req)
+
+		assert.Equal(t, tc.status, rec.Code, tc.name)
+		if tc.status == http.StatusOK {
+			assert.Equal(t, tc.body, rec.Body.String(), tc.name)
+		}
+	}
+
+	t.Run("hmac", func(t *testing.T) {
+		_, secret, _ := strings.Cut(importerToken, ".")
+		signingKey := SigningKey(secret)
+
+		sign := func(timestamp int64, body string) *http.Request {
+			req := httptest.NewRequest("POST", "/v1/import?submitter=ci", strings.NewReader(body))
+			req.Header.Set(HeaderKeyID, importer.ID)
+			req.Header.Set(HeaderTimestamp, fmt.Sprint(timestamp))
+			req.Header.Set(HeaderSignature, hex.EncodeToString(Sign(signingKey, timestamp, "POST", "/v1/import?submitter=ci", []byte(body))))
+			return req
+		}
+
+		rec := httptest.NewRecorder()
+		handler(RoleImporter)(rec, sign(time.Now().Unix(), `{"function":[]}`))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `ci{"function":[]}`, rec.Body.String(), "body should still be readable")
+
+		rec = httptest.NewRecorder()
+		req := sign(time.Now().Unix(), `{"function":[]}`)
+		req.Body = io.NopCloser(strings.NewReader(`{"function":["tampered()"]}`))
+		handler(RoleImporter)(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+		rec = httptest.NewRecorder()
+		handler(RoleImporter)(rec, sign(time.Now().Add(-time.Hour).Unix(), ``))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+		// the stored hash of the secret isn't enough to sign requests
+		secretHash := sha256.Sum256([]byte(secret))
+		now := time.Now().Unix()
+		rec = httptest.NewRecorder()
+		req = sign(now, ``)
+		req.Header.Set(HeaderSignature, hex.EncodeToString(Sign(secretHash[:], now, "POST", "/v1/import?submitter=ci", nil)))
+		handler(RoleImporter)(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+func Test_ParseStaticKeys(t *testing.T) {
+	_, err := ParseStaticKeys([]string{"ci:superuser:id.secret"})
+	assert.Error(t, err)
+
+	_, err = ParseStaticKeys([]string{"ci:importer"})
+	assert.Error(t, err)
+
+	_, err = ParseStaticKeys([]string{"ci:importer:nodot"})
+	assert.Error(t, err)
+}
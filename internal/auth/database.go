@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/database"
+)
+
+// DatabaseKeyStore stores keys in the api_key table, which services using it must create in their own migrations:
+//
+//	CREATE TABLE api_key
+//	(
+//	    id          varchar PRIMARY KEY,
+//	    name        varchar     NOT NULL,
+//	    role        varchar     NOT NULL,
+//	    secret_hash bytea       NOT NULL,
+//	    signing_key bytea,
+//	    created_at  timestamptz NOT NULL DEFAULT now(),
+//	    revoked_at  timestamptz
+//	);
+//
+// Signing keys are stored encrypted with aes-gcm, so that a copy of the table isn't enough to sign requests. Without
+// an encryption key they aren't stored at all, and keys may only be presented as bearer tokens.
+type DatabaseKeyStore struct {
+	db   *database.Database
+	aead cipher.AEAD
+}
+
+// NewDatabaseKeyStore creates a key store which encrypts signing keys with encryptionKey, which must be 16, 24 or 32
+// bytes long, or nil to not store signing keys
+func NewDatabaseKeyStore(db *database.Database, encryptionKey []byte) (*DatabaseKeyStore, error) {
+	store := &DatabaseKeyStore{db: db}
+	if encryptionKey != nil {
+		block, err := aes.NewCipher(encryptionKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encryption key: %w", err)
+		}
+		store.aead, err = cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return store, nil
+}
+
+func scanKey(row pgx.Row) (*Key, []byte, error) {
+	var key Key
+	var encryptedSigningKey []byte
+	if err := row.Scan(&key.ID, &key.Name, &key.Role, &key.SecretHash, &encryptedSigningKey, &key.CreatedAt, &key.RevokedAt); err != nil {
+		return nil, nil, err
+	}
+	return &key, encryptedSigningKey, nil
+}
+
+func (s *DatabaseKeyStore) encrypt(id string, signingKey []byte) ([]byte, error) {
+	if s.aead == nil {
+		return nil, nil
+	}
+
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	// the id is authenticated too, so that a signing key can't be moved onto another key
+	return s.aead.Seal(nonce, nonce, signingKey, []byte(id)), nil
+}
+
+func (s *DatabaseKeyStore) decrypt(id string, encrypted []byte) ([]byte, error) {
+	if s.aead == nil || encrypted == nil {
+		return nil, nil
+	}
+
+	if len(encrypted) < s.aead.NonceSize() {
+		return nil, fmt.Errorf("invalid signing key for key %s", id)
+	}
+	nonce, ciphertext := encrypted[:s.aead.NonceSize()], encrypted[s.aead.NonceSize():]
+	signingKey, err := s.aead.Open(nil, nonce, ciphertext, []byte(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt signing key for key %s: %w", id, err)
+	}
+	return signingKey, nil
+}
+
+func (s *DatabaseKeyStore) LookupKey(ctx context.Context, id string) (*Key, error) {
+	key, encryptedSigningKey, err := scanKey(s.db.QueryRow(ctx, `SELECT id, name, role, secret_hash, signing_key, created_at, revoked_at FROM api_key WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	key.SigningKey, err = s.decrypt(key.ID, encryptedSigningKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// CreateKey generates and stores a new key, returning the token which should be handed to its owner
+func (s *DatabaseKeyStore) CreateKey(ctx context.Context, name string, role Role) (*Key, string, error) {
+	key, token, err := GenerateKey(name, role)
+	if err != nil {
+		return nil, "", err
+	}
+
+	encryptedSigningKey, err := s.encrypt(key.ID, key.SigningKey)
+	if err != nil {
+		return nil, "", err
+	}
+	if encryptedSigningKey == nil {
+		key.SigningKey = nil
+	}
+
+	if err := s.db.QueryRow(ctx, `INSERT INTO api_key (id, name, role, secret_hash, signing_key) VALUES ($1, $2, $3, $4, $5) RETURNING created_at`,
+		key.ID, key.Name, key.Role, key.SecretHash, encryptedSigningKey).Scan(&key.CreatedAt); err != nil {
+		return nil, "", err
+	}
+
+	return key, token, nil
+}
+
+func (s *DatabaseKeyStore) ListKeys(ctx context.Context) ([]*Key, error) {
+	var keys []*Key
+
+	if err := s.db.QuerySimple(func(rows pgx.Rows) error {
+		for rows.Next() {
+			key, _, err := scanKey(rows)
+			if err != nil {
+				return err
+			}
+			keys = append(keys, key)
+		}
+		return nil
+	}, `SELECT id, name, role, secret_hash, signing_key, created_at, revoked_at FROM api_key ORDER BY created_at`); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (s *DatabaseKeyStore) RevokeKey(ctx context.Context, id string) (bool, error) {
+	res, err := s.db.Exec(ctx, `UPDATE api_key SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL`, id)
+	if err != nil {
+		return false, err
+	}
+	return res.RowsAffected() > 0, nil
+}
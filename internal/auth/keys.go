@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// GenerateKey creates a new key and returns it along with the token which should be handed to its owner
+func GenerateKey(name string, role Role) (*Key, string, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, "", err
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", err
+	}
+
+	encodedSecret := base64.RawURLEncoding.EncodeToString(secret)
+	secretHash := sha256.Sum256([]byte(encodedSecret))
+
+	key := &Key{
+		ID:         hex.EncodeToString(id),
+		Name:       name,
+		Role:       role,
+		SecretHash: secretHash[:],
+		SigningKey: SigningKey(encodedSecret),
+	}
+
+	return key, key.ID + "." + encodedSecret, nil
+}
+
+type StaticKeyStore struct {
+	keys map[string]*Key
+}
+
+// ParseStaticKeys parses keys of the form "<name>:<role>:<id>.<secret>", as found in service configs
+func ParseStaticKeys(entries []string) (*StaticKeyStore, error) {
+	store := &StaticKeyStore{
+		keys: make(map[string]*Key),
+	}
+
+	for _, entry := range entries {
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid key: expected name:role:token")
+		}
+
+		role := Role(parts[1])
+		if !role.Valid() {
+			return nil, fmt.Errorf("invalid role for key %s: %s", parts[0], parts[1])
+		}
+
+		id, secret, ok := strings.Cut(parts[2], ".")
+		if !ok {
+			return nil, fmt.Errorf("invalid token for key %s", parts[0])
+		}
+
+		secretHash := sha256.Sum256([]byte(secret))
+		store.keys[id] = &Key{
+			ID:         id,
+			Name:       parts[0],
+			Role:       role,
+			SecretHash: secretHash[:],
+			SigningKey: SigningKey(secret),
+		}
+	}
+
+	return store, nil
+}
+
+func (s *StaticKeyStore) LookupKey(ctx context.Context, id string) (*Key, error) {
+	return s.keys[id], nil
+}
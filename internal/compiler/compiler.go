@@ -1,5 +1,38 @@
 package compiler
 
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+)
+
 type Compiler interface {
-	CompileFromString(string) (map[string]*Contract, error)
+	CompileFromString(ctx context.Context, src string) (map[string]*Contract, error)
+}
+
+// runSandboxed runs a compiler over input which may come from anyone. It runs in an empty scratch directory and is
+// never given --allow-paths, so imports have no files to read, and it's killed once ctx is done
+func runSandboxed(ctx context.Context, name string, path string, args []string, stdin io.Reader) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "compile")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scratch dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var stderr, stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Dir = dir
+	cmd.Stdin = stdin
+	cmd.Stderr = &stderr
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("%s: %w", name, ctx.Err())
+		}
+		return nil, fmt.Errorf("%s: %v\n%s", name, err, stderr.Bytes())
+	}
+	return stdout.Bytes(), nil
 }
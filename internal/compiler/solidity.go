@@ -20,6 +20,7 @@ package compiler
 import (
 	"archive/zip"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -120,10 +121,14 @@ type solcOutputV8 struct {
 }
 
 func (s *Solidity) makeArgs() []string {
+	return append(s.makeOutputArgs(), "--allow-paths", "., ./, ../") // default to support relative paths
+}
+
+// makeOutputArgs are the arguments which select what's compiled, without letting solc read any other files
+func (s *Solidity) makeOutputArgs() []string {
 	p := []string{
 		"--combined-json", "bin,bin-runtime,srcmap,srcmap-runtime,abi,userdoc,devdoc",
-		"--optimize",                  // code optimizer switched on
-		"--allow-paths", "., ./, ../", // default to support relative paths
+		"--optimize", // code optimizer switched on
 	}
 	if s.Major > 0 || s.Minor > 4 || s.Patch > 6 {
 		p[1] += ",metadata,hashes"
@@ -289,7 +294,8 @@ type SolidityCompiler struct {
 	path    string
 }
 
-func NewSolidityCompiler(version string) (Compiler, error) {
+// NewSolidityCompiler returns a compiler for the version, downloading it first if it isn't already
+func NewSolidityCompiler(ctx context.Context, version string) (*SolidityCompiler, error) {
 	compilerDir := path.Join(os.TempDir(), "solidity", version)
 
 	var compilerPath string
@@ -303,7 +309,7 @@ func NewSolidityCompiler(version string) (Compiler, error) {
 		if !os.IsNotExist(err) {
 			return nil, fmt.Errorf("failed to check compiler: %w", err)
 		}
-		err = downloadSolidityCompiler(version, compilerDir)
+		err = downloadSolidityCompiler(ctx, version, compilerDir)
 		if err != nil {
 			return nil, fmt.Errorf("failed to download compiler: %w", err)
 		}
@@ -315,7 +321,7 @@ func NewSolidityCompiler(version string) (Compiler, error) {
 	}, nil
 }
 
-func downloadSolidityCompiler(version string, dest string) error {
+func downloadSolidityCompiler(ctx context.Context, version string, dest string) error {
 	err := os.MkdirAll(dest, os.FileMode(0755))
 	if err != nil {
 		return fmt.Errorf("failed to create parent dir: %w", err)
@@ -330,7 +336,11 @@ func downloadSolidityCompiler(version string, dest string) error {
 		return fmt.Errorf("unsupported os %s", runtime.GOOS)
 	}
 
-	resp, err := http.Get(url)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to construct request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to download solidity v%s: %w", version, err)
 	}
@@ -379,8 +389,22 @@ func downloadSolidityCompiler(version string, dest string) error {
 	return nil
 }
 
-func (c *SolidityCompiler) CompileFromString(src string) (map[string]*Contract, error) {
-	contracts, err := CompileSolidityString(c.path, src)
+// CompileFromString compiles a single source file, which mustn't need to import anything
+func (c *SolidityCompiler) CompileFromString(ctx context.Context, src string) (map[string]*Contract, error) {
+	if len(src) == 0 {
+		return nil, errors.New("solc: empty source string")
+	}
+	s, err := SolidityVersion(c.path)
+	if err != nil {
+		return nil, err
+	}
+
+	args := s.makeOutputArgs()
+	out, err := runSandboxed(ctx, "solc", s.Path, append(args, "--", "-"), strings.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+	contracts, err := ParseCombinedJSON(out, src, s.Version, s.Version, strings.Join(args, " "))
 	if err != nil {
 		return nil, err
 	}
@@ -610,7 +634,15 @@ type StandardJsonInput struct {
 	Settings map[string]any                     `json:"settings"`
 }
 
-func (c *SolidityCompiler) CompileFromStandardJSON(input *StandardJsonInput) (*StandardJsonOutput, error) {
+// CompileFromStandardJSON compiles a standard json input. Every source must be given as content, since solc isn't
+// allowed to read files or urls, and compiler errors are in the output rather than returned
+func (c *SolidityCompiler) CompileFromStandardJSON(ctx context.Context, input *StandardJsonInput) (*StandardJsonOutput, error) {
+	for name, source := range input.Sources {
+		if len(source.URLs) > 0 {
+			return nil, fmt.Errorf("source %s must be given as content rather than urls", name)
+		}
+	}
+
 	b, err := json.Marshal(input)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal settings: %w", err)
@@ -620,20 +652,13 @@ func (c *SolidityCompiler) CompileFromStandardJSON(input *StandardJsonInput) (*S
 	if err != nil {
 		return nil, err
 	}
-	cmd := exec.Command(s.Path, "--standard-json")
-	cmd.Stdin = bytes.NewReader(b)
-	var stderr, stdout bytes.Buffer
-	cmd.Stderr = &stderr
-	cmd.Stdout = &stdout
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("solc: %v\n%s", err, stderr.Bytes())
+	stdout, err := runSandboxed(ctx, "solc", s.Path, []string{"--standard-json"}, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
 	}
-	//fmt.Println(string(stdout.Bytes()))
-
-	os.WriteFile("/tmp/out.json", stdout.Bytes(), os.FileMode(0755))
 
 	var out StandardJsonOutput
-	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+	if err := json.Unmarshal(stdout, &out); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal: %w", err)
 	}
 	return &out, nil
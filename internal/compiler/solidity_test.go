@@ -17,6 +17,7 @@
 package compiler
 
 import (
+	"context"
 	"os/exec"
 	"testing"
 )
@@ -76,3 +77,46 @@ func TestSolidityCompileError(t *testing.T) {
 	}
 	t.Logf("error: %v", err)
 }
+
+func TestSolidityStandardJSON(t *testing.T) {
+	skipWithoutSolc(t)
+
+	input := &StandardJsonInput{
+		Language: "Solidity",
+		Sources: map[string]*StandardJsonSourceFile{
+			"test.sol": {Content: testSource},
+		},
+		Settings: map[string]any{
+			"outputSelection": map[string]any{
+				"*": map[string]any{"*": []string{"abi"}},
+			},
+		},
+	}
+
+	c := &SolidityCompiler{path: "solc"}
+	output, err := c.CompileFromStandardJSON(context.Background(), input)
+	if err != nil {
+		t.Fatalf("error compiling standard json input: %v", err)
+	}
+	contract, ok := output.Contracts["test.sol"]["test"]
+	if !ok {
+		t.Fatal("info for contract 'test.sol:test' not present in result")
+	}
+	if contract.ABI == nil {
+		t.Error("empty abi")
+	}
+}
+
+func TestSolidityStandardJSONURLs(t *testing.T) {
+	input := &StandardJsonInput{
+		Language: "Solidity",
+		Sources: map[string]*StandardJsonSourceFile{
+			"test.sol": {URLs: []string{"/etc/passwd"}},
+		},
+	}
+
+	c := &SolidityCompiler{path: "solc"}
+	if _, err := c.CompileFromStandardJSON(context.Background(), input); err == nil {
+		t.Error("expected sources given as urls to be rejected")
+	}
+}
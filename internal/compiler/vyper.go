@@ -19,6 +19,7 @@ package compiler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -153,7 +154,8 @@ type VyperCompiler struct {
 	path    string
 }
 
-func NewVyperCompiler(version string) (Compiler, error) {
+// NewVyperCompiler returns a compiler for the version, downloading it first if it isn't already
+func NewVyperCompiler(ctx context.Context, version string) (*VyperCompiler, error) {
 	compilerDir := path.Join(os.TempDir(), "vyper", version)
 
 	var compilerPath string
@@ -167,7 +169,7 @@ func NewVyperCompiler(version string) (Compiler, error) {
 		if !os.IsNotExist(err) {
 			return nil, fmt.Errorf("failed to check compiler: %w", err)
 		}
-		err = downloadVyperCompiler(version, compilerDir)
+		err = downloadVyperCompiler(ctx, version, compilerDir)
 		if err != nil {
 			return nil, fmt.Errorf("failed to download compiler: %w", err)
 		}
@@ -179,13 +181,21 @@ func NewVyperCompiler(version string) (Compiler, error) {
 	}, nil
 }
 
-func downloadVyperCompiler(version string, dest string) error {
+func downloadVyperCompiler(ctx context.Context, version string, dest string) error {
 	err := os.MkdirAll(dest, os.FileMode(0755))
 	if err != nil {
 		return fmt.Errorf("failed to create parent dir: %w", err)
 	}
 
-	resp, err := http.Get(fmt.Sprintf(`https://api.github.com/repos/vyperlang/vyper/releases/tags/v%s`, version))
+	get := func(url string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+		return http.DefaultClient.Do(req)
+	}
+
+	resp, err := get(fmt.Sprintf(`https://api.github.com/repos/vyperlang/vyper/releases/tags/v%s`, version))
 	if err != nil {
 		return fmt.Errorf("failed to get release manifest: %w", err)
 	}
@@ -217,7 +227,7 @@ func downloadVyperCompiler(version string, dest string) error {
 		return fmt.Errorf("unsupported os %s", runtime.GOOS)
 	}
 
-	resp, err = http.Get(url)
+	resp, err = get(url)
 	if err != nil {
 		return fmt.Errorf("failed to download solidity v%s: %w", version, err)
 	}
@@ -236,24 +246,16 @@ func downloadVyperCompiler(version string, dest string) error {
 	return nil
 }
 
-func (c *VyperCompiler) CompileFromString(src string) (map[string]*Contract, error) {
+// CompileFromString compiles a single source file, which mustn't need to import anything
+func (c *VyperCompiler) CompileFromString(ctx context.Context, src string) (map[string]*Contract, error) {
 	s, err := VyperVersion(c.path)
 	if err != nil {
 		return nil, err
 	}
 	args := s.makeArgs()
-	cmd := exec.Command(s.Path, append(args, "/dev/stdin")...)
-	return c.run(s, cmd, src)
-}
-
-func (c *VyperCompiler) run(s *Vyper, cmd *exec.Cmd, source string) (map[string]*Contract, error) {
-	var stderr, stdout bytes.Buffer
-	cmd.Stdin = strings.NewReader(source)
-	cmd.Stderr = &stderr
-	cmd.Stdout = &stdout
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("vyper: %v\n%s", err, stderr.Bytes())
+	out, err := runSandboxed(ctx, "vyper", s.Path, append(args, "/dev/stdin"), strings.NewReader(src))
+	if err != nil {
+		return nil, err
 	}
-
-	return ParseVyperJSON(stdout.Bytes(), source, s.Version, s.Version, strings.Join(s.makeArgs(), " "))
+	return ParseVyperJSON(out, src, s.Version, s.Version, strings.Join(args, " "))
 }
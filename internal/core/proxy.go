@@ -0,0 +1,110 @@
+package core
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+var forwardingHeaders = []string{"CF-Connecting-IP", "X-Forwarded-For", "X-Real-IP"}
+
+// TrustedProxies are the networks whose forwarding headers are believed. Requests from anywhere else are attributed to
+// the address they came from, no matter what headers they carry
+type TrustedProxies struct {
+	networks []*net.IPNet
+	// cloudflare is whether CF-Connecting-IP is believed too, which only makes sense when the trusted networks are
+	// cloudflare's, as anything else forwards the header untouched
+	cloudflare bool
+}
+
+// ParseTrustedProxies parses a list of CIDRs or bare addresses, optionally also trusting CF-Connecting-IP when it was
+// set by one of them
+func ParseTrustedProxies(entries []string, trustCloudflare bool) (TrustedProxies, error) {
+	proxies := TrustedProxies{cloudflare: trustCloudflare}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return TrustedProxies{}, fmt.Errorf("invalid trusted proxy: %s", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			proxies.networks = append(proxies.networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return TrustedProxies{}, fmt.Errorf("invalid trusted proxy: %s: %w", entry, err)
+		}
+		proxies.networks = append(proxies.networks, network)
+	}
+	return proxies, nil
+}
+
+func (p TrustedProxies) Contains(ip net.IP) bool {
+	for _, network := range p.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP works out the address of the client, only following forwarding headers set by trusted proxies
+func (p TrustedProxies) ClientIP(req *http.Request) string {
+	peer, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		peer = req.RemoteAddr
+	}
+	if !p.Contains(net.ParseIP(peer)) {
+		return peer
+	}
+
+	if ip := strings.TrimSpace(req.Header.Get("CF-Connecting-IP")); p.cloudflare && net.ParseIP(ip) != nil {
+		return ip
+	}
+
+	if forwarded := req.Header.Values("X-Forwarded-For"); len(forwarded) != 0 {
+		// each proxy appends the address it saw, so walk back until we find one we didn't add ourselves
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+		client := peer
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(hops[i]))
+			if ip == nil {
+				break
+			}
+			client = ip.String()
+			if !p.Contains(ip) {
+				break
+			}
+		}
+		return client
+	}
+
+	if ip := strings.TrimSpace(req.Header.Get("X-Real-IP")); net.ParseIP(ip) != nil {
+		return ip
+	}
+
+	return peer
+}
+
+// Handler resolves the client address once and stores it as the request's remote address, dropping the forwarding
+// headers so that GetRemoteIP can't be fooled further down the chain
+func (p TrustedProxies) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.RemoteAddr = net.JoinHostPort(p.ClientIP(r), "0")
+		for _, header := range forwardingHeaders {
+			r.Header.Del(header)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_TrustedProxies(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1", "::1"}, true)
+	assert.NoError(t, err)
+
+	_, err = ParseTrustedProxies([]string{"not-an-ip"}, false)
+	assert.Error(t, err)
+
+	for _, tc := range []struct {
+		name    string
+		remote  string
+		headers map[string]string
+		want    string
+	}{
+		{"direct", "1.2.3.4:5678", nil, "1.2.3.4"},
+		{"spoofed forwarded for", "1.2.3.4:5678", map[string]string{"X-Forwarded-For": "5.6.7.8"}, "1.2.3.4"},
+		{"spoofed cloudflare", "1.2.3.4:5678", map[string]string{"CF-Connecting-IP": "5.6.7.8"}, "1.2.3.4"},
+		{"trusted cloudflare", "10.1.2.3:5678", map[string]string{"CF-Connecting-IP": "5.6.7.8"}, "5.6.7.8"},
+		{"trusted forwarded for", "10.1.2.3:5678", map[string]string{"X-Forwarded-For": "5.6.7.8"}, "5.6.7.8"},
+		{"forwarded for chain", "10.1.2.3:5678", map[string]string{"X-Forwarded-For": "9.9.9.9, 5.6.7.8, 192.168.1.1"}, "5.6.7.8"},
+		{"forwarded for garbage", "10.1.2.3:5678", map[string]string{"X-Forwarded-For": "garbage, 10.0.0.2"}, "10.0.0.2"},
+		{"trusted real ip", "[::1]:5678", map[string]string{"X-Real-IP": "5.6.7.8"}, "5.6.7.8"},
+		{"trusted no headers", "10.1.2.3:5678", nil, "10.1.2.3"},
+	} {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = tc.remote
+		for k, v := range tc.headers {
+			req.Header.Set(k, v)
+		}
+		assert.Equal(t, tc.want, proxies.ClientIP(req), tc.name)
+
+		var seen string
+		proxies.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			seen = GetRemoteIP(r)
+		})).ServeHTTP(httptest.NewRecorder(), req)
+		assert.Equal(t, tc.want, seen, tc.name)
+	}
+
+	// cloudflare's header isn't believed unless asked for, even from trusted proxies
+	proxies, err = ParseTrustedProxies([]string{"10.0.0.0/8"}, false)
+	assert.NoError(t, err)
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.1.2.3:5678"
+	req.Header.Set("CF-Connecting-IP", "5.6.7.8")
+	assert.Equal(t, "10.1.2.3", proxies.ClientIP(req))
+}
@@ -1,34 +1,163 @@
 package discord
 
 import (
+	"context"
 	"fmt"
 	"github.com/bwmarrin/discordgo"
 	log "github.com/sirupsen/logrus"
+	"time"
+	"unicode/utf8"
 )
 
 type Client struct {
 	Session *discordgo.Session
+
+	guild    string
+	commands map[string]*Command
+}
+
+// Command is a slash command. Handlers are given the command's options by name and reply with markdown, which is
+// truncated to fit in a message
+type Command struct {
+	Command *discordgo.ApplicationCommand
+	Handler func(ctx context.Context, i *discordgo.InteractionCreate, options map[string]string) (string, error)
+}
+
+type Option func(c *Client)
+
+// WithCommands registers slash commands once the bot has logged in
+func WithCommands(commands ...*Command) Option {
+	return func(c *Client) {
+		for _, command := range commands {
+			c.commands[command.Command.Name] = command
+		}
+	}
+}
+
+// WithGuild registers commands to a single guild rather than globally, which makes changes visible immediately
+func WithGuild(guild string) Option {
+	return func(c *Client) {
+		c.guild = guild
+	}
 }
 
-func New(token string) (*Client, error) {
+// discord rejects messages longer than this
+const MaxMessageLength = 2000
+
+// how long a command has to respond, after which the deferred response is left as an error
+const commandTimeout = 30 * time.Second
+
+func New(token string, options ...Option) (*Client, error) {
 	session, err := discordgo.New("Bot " + token)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create discord client: %w", err)
 	}
 
+	client := &Client{
+		Session:  session,
+		commands: make(map[string]*Command),
+	}
+	for _, option := range options {
+		option(client)
+	}
+
 	session.AddHandler(func(s *discordgo.Session, r *discordgo.Ready) {
 		log.WithFields(log.Fields{
 			"username": fmt.Sprintf("%s#%s", s.State.User.Username, s.State.User.Discriminator),
 		}).Infof("logged in to discord")
 
-		// register application commands if any
+		if err := client.registerCommands(); err != nil {
+			log.WithError(err).Errorf("failed to register discord commands")
+		}
 	})
+	session.AddHandler(client.handleInteraction)
 
 	if err := session.Open(); err != nil {
 		return nil, fmt.Errorf("failed to open session: %w", err)
 	}
 
-	return &Client{
-		Session: session,
-	}, nil
+	return client, nil
+}
+
+func (c *Client) registerCommands() error {
+	if len(c.commands) == 0 {
+		return nil
+	}
+
+	var commands []*discordgo.ApplicationCommand
+	for _, command := range c.commands {
+		commands = append(commands, command.Command)
+	}
+
+	_, err := c.Session.ApplicationCommandBulkOverwrite(c.Session.State.User.ID, c.guild, commands)
+	return err
+}
+
+func (c *Client) handleInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	data := i.ApplicationCommandData()
+	command, ok := c.commands[data.Name]
+	if !ok {
+		return
+	}
+
+	// commands may hit the database, so acknowledge now and fill in the reply when it's ready
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	}); err != nil {
+		log.WithError(err).WithField("command", data.Name).Errorf("failed to acknowledge discord command")
+		return
+	}
+
+	options := make(map[string]string)
+	for _, option := range data.Options {
+		options[option.Name] = fmt.Sprint(option.Value)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	content, err := command.Handler(ctx, i, options)
+	if err != nil {
+		log.WithError(err).WithFields(log.Fields{
+			"command": data.Name,
+			"user":    InteractionUser(i).String(),
+		}).Errorf("failed to run discord command")
+
+		// errors may carry internal details like database messages, so they only go to the log
+		content = "Something went wrong running this command, try again later"
+	}
+	content = Truncate(content)
+
+	if _, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: &content,
+	}); err != nil {
+		log.WithError(err).WithField("command", data.Name).Errorf("failed to reply to discord command")
+	}
+}
+
+// Truncate shortens content to fit in a message, cutting on a rune boundary so the message stays valid utf-8
+func Truncate(content string) string {
+	if len(content) <= MaxMessageLength {
+		return content
+	}
+	n := MaxMessageLength - len("...")
+	for n > 0 && !utf8.RuneStart(content[n]) {
+		n--
+	}
+	return content[:n] + "..."
+}
+
+// InteractionUser returns whoever triggered an interaction, which is reported differently in guilds and DMs
+func InteractionUser(i *discordgo.InteractionCreate) *discordgo.User {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User
+	}
+	if i.User != nil {
+		return i.User
+	}
+	return &discordgo.User{}
 }
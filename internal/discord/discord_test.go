@@ -0,0 +1,24 @@
+package discord
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func Test_Truncate(t *testing.T) {
+	assert.Equal(t, "short", Truncate("short"))
+
+	exact := strings.Repeat("a", MaxMessageLength)
+	assert.Equal(t, exact, Truncate(exact))
+
+	long := Truncate(strings.Repeat("a", MaxMessageLength+1))
+	assert.Len(t, long, MaxMessageLength)
+	assert.True(t, strings.HasSuffix(long, "..."))
+
+	// the cut would land in the middle of a multi-byte rune
+	multibyte := Truncate(strings.Repeat("é", MaxMessageLength))
+	assert.True(t, utf8.ValidString(multibyte))
+	assert.LessOrEqual(t, len(multibyte), MaxMessageLength)
+}
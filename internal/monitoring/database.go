@@ -0,0 +1,54 @@
+package monitoring
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Pool is anything backed by a pgx connection pool, such as a *database.Database
+type Pool interface {
+	Ping(ctx context.Context) error
+	Stat() *pgxpool.Stat
+}
+
+var (
+	poolAcquiredConns = prometheus.NewDesc("db_pool_acquired_conns", "Connections currently in use.", nil, nil)
+	poolIdleConns     = prometheus.NewDesc("db_pool_idle_conns", "Connections currently idle.", nil, nil)
+	poolTotalConns    = prometheus.NewDesc("db_pool_total_conns", "Connections currently open, including those being established.", nil, nil)
+	poolMaxConns      = prometheus.NewDesc("db_pool_max_conns", "Most connections the pool will open.", nil, nil)
+	poolAcquires      = prometheus.NewDesc("db_pool_acquires_total", "Connections acquired from the pool.", nil, nil)
+	poolEmptyAcquires = prometheus.NewDesc("db_pool_empty_acquires_total", "Acquires which had to wait for a connection.", nil, nil)
+	poolAcquireTime   = prometheus.NewDesc("db_pool_acquire_seconds_total", "Time spent acquiring connections.", nil, nil)
+)
+
+type poolCollector struct {
+	pool Pool
+}
+
+func (p *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- poolAcquiredConns
+	ch <- poolIdleConns
+	ch <- poolTotalConns
+	ch <- poolMaxConns
+	ch <- poolAcquires
+	ch <- poolEmptyAcquires
+	ch <- poolAcquireTime
+}
+
+func (p *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := p.pool.Stat()
+	ch <- prometheus.MustNewConstMetric(poolAcquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(poolIdleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(poolTotalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(poolMaxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(poolAcquires, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolEmptyAcquires, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolAcquireTime, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+}
+
+// AddDatabase exports the pool's connection stats, and fails /readyz while the database can't be reached
+func (m *Monitor) AddDatabase(pool Pool) {
+	m.MustRegister(&poolCollector{pool: pool})
+	m.AddCheck("database", pool.Ping)
+}
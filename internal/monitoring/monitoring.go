@@ -0,0 +1,233 @@
+// Package monitoring gives every service the same /metrics, /healthz and /readyz endpoints. /healthz only reports
+// that the process is serving requests, while /readyz also runs the checks the service registered, like database
+// connectivity. How stale background tasks are is exported as metrics, and can also fail /readyz past a maximum age,
+// which is best left to tasks whose staleness is particular to a replica. None of the endpoints are authenticated or
+// rate limited, so they're served from their own handler, meant for a port which is only reachable from inside the
+// deployment.
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// checkTimeout bounds how long /readyz waits for any one check
+const checkTimeout = 5 * time.Second
+
+// Check returns an error if whatever it checks isn't working
+type Check func(ctx context.Context) error
+
+type Monitor struct {
+	registry   *prometheus.Registry
+	registerer prometheus.Registerer
+	started    time.Time
+
+	requests  *prometheus.CounterVec
+	durations *prometheus.HistogramVec
+
+	checksLock sync.RWMutex
+	checks     map[string]Check
+}
+
+// New creates a monitor whose metrics are all labelled with the name of the service
+func New(service string) *Monitor {
+	registry := prometheus.NewRegistry()
+
+	m := &Monitor{
+		registry:   registry,
+		registerer: prometheus.WrapRegistererWith(prometheus.Labels{"service": service}, registry),
+		started:    time.Now(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "requests_total",
+			Help: "Requests served, by protocol, route and response code.",
+		}, []string{"protocol", "route", "code"}),
+		durations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "request_duration_seconds",
+			Help:    "Time taken to serve requests, by protocol and route.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"protocol", "route"}),
+		checks: make(map[string]Check),
+	}
+
+	m.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.durations,
+	)
+
+	return m
+}
+
+// MustRegister registers service specific metrics, panicking if any of them conflict
+func (m *Monitor) MustRegister(cs ...prometheus.Collector) {
+	m.registerer.MustRegister(cs...)
+}
+
+// Observe records a request which wasn't served over http, such as a grpc call
+func (m *Monitor) Observe(protocol string, route string, code string, duration time.Duration) {
+	m.requests.WithLabelValues(protocol, route, code).Inc()
+	m.durations.WithLabelValues(protocol, route).Observe(duration.Seconds())
+}
+
+// AddCheck registers a check which must pass for /readyz to succeed
+func (m *Monitor) AddCheck(name string, check Check) {
+	m.checksLock.Lock()
+	defer m.checksLock.Unlock()
+
+	m.checks[name] = check
+}
+
+// Age returns how long ago last happened. If it hasn't happened yet, the age is measured from when the monitor was
+// created, so that something which never succeeds still goes stale
+func (m *Monitor) Age(last time.Time) time.Duration {
+	if last.IsZero() {
+		last = m.started
+	}
+	return time.Since(last)
+}
+
+// AddAge exports how long ago last returned as a gauge. If maxAge isn't zero, it's also checked by /readyz, which fails
+// once the age is greater
+func (m *Monitor) AddAge(name string, help string, maxAge time.Duration, last func() time.Time) {
+	m.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: name + "_age_seconds",
+		Help: help,
+	}, func() float64 {
+		return m.Age(last()).Seconds()
+	}))
+
+	if maxAge > 0 {
+		m.AddCheck(name, func(ctx context.Context) error {
+			if age := m.Age(last()); age > maxAge {
+				return fmt.Errorf("last happened %s ago, more than %s", age.Round(time.Second), maxAge)
+			}
+			return nil
+		})
+	}
+}
+
+// Middleware records the count and duration of requests to each route. Routes are labelled by their template rather
+// than the requested path, so that path parameters don't create a series per value
+func (m *Monitor) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := r.URL.Path
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+		route = r.Method + " " + route
+
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		m.Observe("http", route, strconv.Itoa(recorder.status), time.Since(start))
+	})
+}
+
+// Instrument records metrics for every route on the router
+func (m *Monitor) Instrument(router *mux.Router) {
+	router.Use(m.Middleware)
+}
+
+// Handler serves /metrics, /healthz and /readyz. It isn't authenticated, so it should only be reachable internally
+func (m *Monitor) Handler() http.Handler {
+	router := mux.NewRouter()
+	router.Handle("/metrics", promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})).Methods("GET")
+	router.HandleFunc("/healthz", m.serveHealth).Methods("GET")
+	router.HandleFunc("/readyz", m.serveReady).Methods("GET")
+	return router
+}
+
+// Ready runs every check concurrently, returning each one's error by name, or nil for those that passed
+func (m *Monitor) Ready(ctx context.Context) map[string]error {
+	m.checksLock.RLock()
+	checks := make(map[string]Check, len(m.checks))
+	for name, check := range m.checks {
+		checks[name] = check
+	}
+	m.checksLock.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	results := make(map[string]error, len(checks))
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+
+			err := check(ctx)
+			lock.Lock()
+			results[name] = err
+			lock.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+
+	return results
+}
+
+type checkResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+func (m *Monitor) serveHealth(w http.ResponseWriter, r *http.Request) {
+	writeCheckResponse(w, http.StatusOK, &checkResponse{Status: "ok"})
+}
+
+func (m *Monitor) serveReady(w http.ResponseWriter, r *http.Request) {
+	results := m.Ready(r.Context())
+
+	resp := &checkResponse{Status: "ok", Checks: make(map[string]string)}
+	status := http.StatusOK
+	for name, err := range results {
+		if err != nil {
+			resp.Checks[name] = err.Error()
+			resp.Status = "failed"
+			status = http.StatusServiceUnavailable
+		} else {
+			resp.Checks[name] = "ok"
+		}
+	}
+
+	writeCheckResponse(w, status, resp)
+}
+
+func writeCheckResponse(w http.ResponseWriter, status int, resp *checkResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+// Flush keeps streamed responses, like exports, streaming through the recorder
+func (s *statusRecorder) Flush() {
+	if flusher, ok := s.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
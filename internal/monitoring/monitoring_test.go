@@ -0,0 +1,100 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_Monitor(t *testing.T) {
+	m := New("test")
+
+	var lastRun time.Time
+	m.AddAge("task", "Time since the task last ran.", 0, func() time.Time {
+		return lastRun
+	})
+	var dbErr error
+	m.AddCheck("database", func(ctx context.Context) error {
+		return dbErr
+	})
+
+	router := mux.NewRouter()
+	m.Instrument(router)
+	router.HandleFunc("/v1/things/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}).Methods("GET")
+	internal := m.Handler()
+
+	serve := func(handler http.Handler, path string) (int, []byte) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		body, _ := io.ReadAll(w.Body)
+		return w.Code, body
+	}
+	get := func(path string) (int, []byte) {
+		return serve(internal, path)
+	}
+
+	ready := func() (int, *checkResponse) {
+		status, body := get("/readyz")
+		var resp checkResponse
+		assert.NoError(t, json.Unmarshal(body, &resp))
+		return status, &resp
+	}
+
+	status, resp := ready()
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, &checkResponse{Status: "ok", Checks: map[string]string{"database": "ok"}}, resp)
+
+	// a stale task without a maximum age is only reported as a metric, while readiness follows the database
+	lastRun = time.Now().Add(-2 * time.Hour)
+	status, _ = ready()
+	assert.Equal(t, http.StatusOK, status)
+
+	dbErr = errors.New("connection refused")
+	status, resp = ready()
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, &checkResponse{Status: "failed", Checks: map[string]string{"database": "connection refused"}}, resp)
+
+	// liveness doesn't depend on the checks
+	status, body := get("/healthz")
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"status": "ok"}`, string(body))
+
+	status, _ = serve(router, "/v1/things/1")
+	assert.Equal(t, http.StatusTeapot, status)
+	serve(router, "/v1/things/2")
+
+	// the monitoring endpoints aren't part of the instrumented router
+	status, _ = serve(router, "/metrics")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	status, body = get("/metrics")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, string(body), `requests_total{code="418",protocol="http",route="GET /v1/things/{id}",service="test"} 2`)
+	assert.Contains(t, string(body), `task_age_seconds{service="test"} 7200`)
+	assert.Contains(t, string(body), `go_goroutines{service="test"}`)
+}
+
+func Test_MonitorMaxAge(t *testing.T) {
+	m := New("test")
+
+	lastRun := time.Now()
+	m.AddAge("task", "Time since the task last ran.", time.Hour, func() time.Time {
+		return lastRun
+	})
+
+	results := m.Ready(context.Background())
+	assert.Equal(t, map[string]error{"task": nil}, results)
+
+	lastRun = time.Now().Add(-2 * time.Hour)
+	results = m.Ready(context.Background())
+	assert.ErrorContains(t, results["task"], "more than 1h0m0s")
+}
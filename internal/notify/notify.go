@@ -0,0 +1,168 @@
+// Package notify sends alerts about notable events to chat services, webhooks or the log
+package notify
+
+import (
+	"context"
+	"fmt"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/discord"
+	log "github.com/sirupsen/logrus"
+	"strings"
+	"time"
+)
+
+type Event string
+
+const (
+	EventDuplicateImport        Event = "duplicate_import"
+	EventCollision              Event = "collision"
+	EventExportCompleted        Event = "export_completed"
+	EventCanonicalRefreshFailed Event = "canonical_refresh_failed"
+)
+
+func Events() []Event {
+	return []Event{EventDuplicateImport, EventCollision, EventExportCompleted, EventCanonicalRefreshFailed}
+}
+
+func (e Event) Valid() bool {
+	for _, event := range Events() {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Message is a single alert. Text is markdown, which every chat service we post to understands, and Data is a
+// machine-readable form of the same information for webhooks
+type Message struct {
+	Event Event
+	Title string
+	Text  string
+	Data  any
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, msg *Message) error
+}
+
+// Sink is a notifier along with the events routed to it. A sink without events receives all of them
+type Sink struct {
+	Notifier Notifier
+	Events   []Event
+}
+
+func (s *Sink) Accepts(event Event) bool {
+	if len(s.Events) == 0 {
+		return true
+	}
+	for _, e := range s.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse builds a sink from a spec of the form "<kind>:<target>", optionally followed by ";"-separated options:
+//
+//	log:
+//	discord:<channel id>
+//	slack:<incoming webhook url>
+//	webhook:<url>;secret=<hmac secret>
+//
+// The events option restricts the sink to some events, such as "discord:123;events=collision|duplicate_import".
+// Discord sinks post through the given client, so it must be set if any are configured.
+func Parse(spec string, discordClient *discord.Client) (*Sink, error) {
+	spec, rawOptions, _ := strings.Cut(spec, ";")
+	kind, target, _ := strings.Cut(spec, ":")
+
+	options := make(map[string]string)
+	if rawOptions != "" {
+		for _, option := range strings.Split(rawOptions, ";") {
+			key, value, ok := strings.Cut(option, "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid option: %s", option)
+			}
+			options[key] = value
+		}
+	}
+
+	sink := &Sink{}
+	if events, ok := options["events"]; ok {
+		for _, event := range strings.Split(events, "|") {
+			if !Event(event).Valid() {
+				return nil, fmt.Errorf("unknown event: %s", event)
+			}
+			sink.Events = append(sink.Events, Event(event))
+		}
+		delete(options, "events")
+	}
+
+	switch kind {
+	case "log":
+		sink.Notifier = &LogNotifier{}
+	case "discord":
+		if discordClient == nil {
+			return nil, fmt.Errorf("discord notifier requires a discord bot token")
+		}
+		if target == "" {
+			return nil, fmt.Errorf("discord notifier requires a channel")
+		}
+		sink.Notifier = NewDiscordNotifier(discordClient, target)
+	case "slack":
+		if !isHTTPURL(target) {
+			return nil, fmt.Errorf("slack notifier requires an http(s) url")
+		}
+		sink.Notifier = NewSlackNotifier(target)
+	case "webhook":
+		if !isHTTPURL(target) {
+			return nil, fmt.Errorf("webhook notifier requires an http(s) url")
+		}
+		sink.Notifier = NewWebhookNotifier(target, []byte(options["secret"]))
+		delete(options, "secret")
+	default:
+		return nil, fmt.Errorf("unknown notifier: %s", kind)
+	}
+
+	for key := range options {
+		return nil, fmt.Errorf("unknown option for %s notifier: %s", kind, key)
+	}
+
+	return sink, nil
+}
+
+func isHTTPURL(target string) bool {
+	return strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://")
+}
+
+// Router fans a message out to the sinks which accept its event in the background, so that slow or broken sinks
+// never hold up the caller. Failures are logged
+type Router struct {
+	sinks   []*Sink
+	timeout time.Duration
+}
+
+func NewRouter(sinks ...*Sink) *Router {
+	return &Router{
+		sinks:   sinks,
+		timeout: 30 * time.Second,
+	}
+}
+
+func (r *Router) Notify(ctx context.Context, msg *Message) error {
+	for _, sink := range r.sinks {
+		if !sink.Accepts(msg.Event) {
+			continue
+		}
+
+		go func(n Notifier) {
+			ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+			defer cancel()
+
+			if err := n.Notify(ctx, msg); err != nil {
+				log.WithError(err).WithField("event", msg.Event).Errorf("failed to send notification")
+			}
+		}(sink.Notifier)
+	}
+	return nil
+}
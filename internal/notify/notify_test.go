@@ -0,0 +1,150 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_Parse(t *testing.T) {
+	sink, err := Parse("log:", nil)
+	assert.NoError(t, err)
+	assert.IsType(t, &LogNotifier{}, sink.Notifier)
+	assert.Empty(t, sink.Events)
+
+	sink, err = Parse("webhook:https://example.com/hook?a=b;secret=hunter2;events=collision|export_completed", nil)
+	assert.NoError(t, err)
+	assert.IsType(t, &WebhookNotifier{}, sink.Notifier)
+	assert.Equal(t, "https://example.com/hook?a=b", sink.Notifier.(*WebhookNotifier).url)
+	assert.Equal(t, []byte("hunter2"), sink.Notifier.(*WebhookNotifier).secret)
+	assert.Equal(t, []Event{EventCollision, EventExportCompleted}, sink.Events)
+	assert.True(t, sink.Accepts(EventCollision))
+	assert.False(t, sink.Accepts(EventDuplicateImport))
+
+	sink, err = Parse("slack:https://hooks.slack.com/services/T/B/X", nil)
+	assert.NoError(t, err)
+	assert.IsType(t, &SlackNotifier{}, sink.Notifier)
+
+	for _, spec := range []string{
+		"",
+		"carrier-pigeon:home",
+		"webhook:example.com",
+		"slack:",
+		"discord:123",
+		"log:;events=everything",
+		"log:;secret=hunter2",
+		"log:;verbose",
+	} {
+		_, err := Parse(spec, nil)
+		assert.Error(t, err, spec)
+	}
+}
+
+func Test_WebhookNotifier(t *testing.T) {
+	var (
+		received map[string]any
+		headers  http.Header
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	err := NewWebhookNotifier(server.URL, nil).Notify(context.Background(), &Message{
+		Event: EventCollision,
+		Title: "title",
+		Text:  "text",
+		Data:  map[string]string{"hash": "0x12345678"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "application/json", headers.Get("Content-Type"))
+	assert.Empty(t, headers.Get(HeaderWebhookSignature))
+	assert.Equal(t, map[string]any{
+		"event": "collision",
+		"title": "title",
+		"text":  "text",
+		"data":  map[string]any{"hash": "0x12345678"},
+	}, received)
+
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	assert.Error(t, NewWebhookNotifier(server.URL, nil).Notify(context.Background(), &Message{Event: EventCollision}))
+}
+
+func Test_WebhookNotifierSigned(t *testing.T) {
+	secret := []byte("hunter2")
+
+	var verified bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		timestamp, err := strconv.ParseInt(r.Header.Get(HeaderWebhookTimestamp), 10, 64)
+		assert.NoError(t, err)
+		assert.InDelta(t, time.Now().Unix(), timestamp, 5)
+
+		verified = r.Header.Get(HeaderWebhookSignature) == SignWebhook(secret, timestamp, body)
+	}))
+	defer server.Close()
+
+	assert.NoError(t, NewWebhookNotifier(server.URL, secret).Notify(context.Background(), &Message{Event: EventExportCompleted}))
+	assert.True(t, verified)
+	assert.NotEqual(t, SignWebhook(secret, 1, []byte("{}")), SignWebhook([]byte("hunter3"), 1, []byte("{}")))
+}
+
+func Test_SlackNotifier(t *testing.T) {
+	var received map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	assert.NoError(t, NewSlackNotifier(server.URL).Notify(context.Background(), &Message{
+		Event: EventCanonicalRefreshFailed,
+		Title: "title",
+		Text:  "text",
+	}))
+	assert.Equal(t, map[string]string{"text": "*title*\ntext"}, received)
+}
+
+type recordingNotifier struct {
+	wg     *sync.WaitGroup
+	lock   sync.Mutex
+	events []Event
+}
+
+func (r *recordingNotifier) Notify(ctx context.Context, msg *Message) error {
+	defer r.wg.Done()
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.events = append(r.events, msg.Event)
+	return nil
+}
+
+func Test_Router(t *testing.T) {
+	var wg sync.WaitGroup
+	all := &recordingNotifier{wg: &wg}
+	collisions := &recordingNotifier{wg: &wg}
+
+	router := NewRouter(
+		&Sink{Notifier: all},
+		&Sink{Notifier: collisions, Events: []Event{EventCollision}},
+	)
+
+	wg.Add(3)
+	assert.NoError(t, router.Notify(context.Background(), &Message{Event: EventDuplicateImport}))
+	assert.NoError(t, router.Notify(context.Background(), &Message{Event: EventCollision}))
+	wg.Wait()
+
+	assert.ElementsMatch(t, []Event{EventDuplicateImport, EventCollision}, all.events)
+	assert.Equal(t, []Event{EventCollision}, collisions.events)
+}
@@ -0,0 +1,144 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/discord"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// LogNotifier writes messages to the log, which is handy when running locally
+type LogNotifier struct{}
+
+func (l *LogNotifier) Notify(ctx context.Context, msg *Message) error {
+	log.WithField("event", msg.Event).Infof("%s\n%s", msg.Title, msg.Text)
+	return nil
+}
+
+type DiscordNotifier struct {
+	client  *discord.Client
+	channel string
+}
+
+func NewDiscordNotifier(client *discord.Client, channel string) *DiscordNotifier {
+	return &DiscordNotifier{client: client, channel: channel}
+}
+
+func (d *DiscordNotifier) Notify(ctx context.Context, msg *Message) error {
+	content := discord.Truncate(fmt.Sprintf("**%s**\n%s", msg.Title, msg.Text))
+
+	_, err := d.client.Session.ChannelMessageSendComplex(d.channel, &discordgo.MessageSend{
+		Content: content,
+	}, discordgo.WithContext(ctx))
+	return err
+}
+
+// post sends a json body, treating any non-2xx status as a failure
+func post(ctx context.Context, client *http.Client, url string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to construct request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return nil
+}
+
+// SlackNotifier posts to a Slack incoming webhook, or anything which accepts the same payload
+type SlackNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func NewSlackNotifier(url string) *SlackNotifier {
+	return &SlackNotifier{url: url, client: &http.Client{}}
+}
+
+func (s *SlackNotifier) Notify(ctx context.Context, msg *Message) error {
+	// slack's mrkdwn bolds with single asterisks but otherwise reads our markdown well enough
+	body, err := json.Marshal(map[string]string{
+		"text": fmt.Sprintf("*%s*\n%s", msg.Title, msg.Text),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	return post(ctx, s.client, s.url, body, nil)
+}
+
+const (
+	HeaderWebhookTimestamp = "X-Timestamp"
+	HeaderWebhookSignature = "X-Signature"
+)
+
+// WebhookNotifier posts each message as json. If it has a secret, the body is signed so that receivers can check
+// where it came from; see SignWebhook
+type WebhookNotifier struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+func NewWebhookNotifier(url string, secret []byte) *WebhookNotifier {
+	return &WebhookNotifier{url: url, secret: secret, client: &http.Client{}}
+}
+
+type webhookPayload struct {
+	Event Event  `json:"event"`
+	Title string `json:"title"`
+	Text  string `json:"text"`
+	Data  any    `json:"data,omitempty"`
+}
+
+// SignWebhook computes the hex encoded HMAC-SHA256 of a webhook delivery, which is sent in the X-Signature header
+// alongside its unix timestamp in X-Timestamp
+func SignWebhook(secret []byte, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	fmt.Fprintf(mac, "%d\n", timestamp)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (w *WebhookNotifier) Notify(ctx context.Context, msg *Message) error {
+	body, err := json.Marshal(&webhookPayload{
+		Event: msg.Event,
+		Title: msg.Title,
+		Text:  msg.Text,
+		Data:  msg.Data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	var headers map[string]string
+	if len(w.secret) > 0 {
+		timestamp := time.Now().Unix()
+		headers = map[string]string{
+			HeaderWebhookTimestamp: strconv.FormatInt(timestamp, 10),
+			HeaderWebhookSignature: SignWebhook(w.secret, timestamp, body),
+		}
+	}
+
+	return post(ctx, w.client, w.url, body, headers)
+}
@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/database"
+	"time"
+)
+
+// DatabaseLimiter keeps buckets in the rate_limit_bucket table so that limits hold across replicas. Services using it
+// must create the table in their own migrations:
+//
+//	CREATE UNLOGGED TABLE rate_limit_bucket
+//	(
+//	    key varchar PRIMARY KEY,
+//	    tat timestamptz NOT NULL
+//	);
+type DatabaseLimiter struct {
+	db *database.Database
+}
+
+func NewDatabaseLimiter(db *database.Database) *DatabaseLimiter {
+	return &DatabaseLimiter{db: db}
+}
+
+// the bucket is only updated when the request fits, so a missing row means the request was refused
+const allowQuery = `
+INSERT INTO rate_limit_bucket (key, tat) VALUES ($1, now() + $2::float8 * interval '1 microsecond')
+ON CONFLICT (key) DO UPDATE SET tat = GREATEST(rate_limit_bucket.tat, now()) + $2::float8 * interval '1 microsecond'
+WHERE GREATEST(rate_limit_bucket.tat, now()) + $2::float8 * interval '1 microsecond' - now() <= $3::float8 * interval '1 microsecond'
+RETURNING tat
+`
+
+func (d *DatabaseLimiter) Allow(ctx context.Context, key string, limit Limit) (bool, time.Duration, error) {
+	if limit.Unlimited() {
+		return true, 0, nil
+	}
+
+	interval, tolerance := float64(limit.cost().Microseconds()), float64(limit.tolerance().Microseconds())
+
+	var tat time.Time
+	err := d.db.QueryRow(ctx, allowQuery, key, interval, tolerance).Scan(&tat)
+	if err == nil {
+		return true, 0, nil
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return false, 0, err
+	}
+
+	var wait float64
+	if err := d.db.QueryRow(ctx,
+		`SELECT EXTRACT(EPOCH FROM tat + $2::float8 * interval '1 microsecond' - now()) - $3::float8 / 1e6 FROM rate_limit_bucket WHERE key = $1`,
+		key, interval, tolerance,
+	).Scan(&wait); err != nil {
+		return false, 0, err
+	}
+	return false, time.Duration(wait * float64(time.Second)), nil
+}
+
+func (d *DatabaseLimiter) Prune(ctx context.Context) error {
+	_, err := d.db.Exec(ctx, `DELETE FROM rate_limit_bucket WHERE tat < now()`)
+	return err
+}
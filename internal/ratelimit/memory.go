@@ -0,0 +1,49 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryLimiter keeps buckets in process, so each replica enforces limits separately
+type MemoryLimiter struct {
+	lock    sync.Mutex
+	buckets map[string]time.Time
+	now     func() time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		buckets: make(map[string]time.Time),
+		now:     time.Now,
+	}
+}
+
+func (m *MemoryLimiter) Allow(ctx context.Context, key string, limit Limit) (bool, time.Duration, error) {
+	if limit.Unlimited() {
+		return true, 0, nil
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	tat, wait, ok := take(m.now(), m.buckets[key], limit)
+	if ok {
+		m.buckets[key] = tat
+	}
+	return ok, wait, nil
+}
+
+func (m *MemoryLimiter) Prune(ctx context.Context) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	now := m.now()
+	for key, tat := range m.buckets {
+		if tat.Before(now) {
+			delete(m.buckets, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// Limit describes a token bucket which refills at Rate tokens per second and holds at most Burst tokens. Each request
+// takes Cost tokens, or one if it's not set
+type Limit struct {
+	Rate  float64
+	Burst int
+	Cost  int
+}
+
+// Unlimited reports whether the limit lets everything through
+func (l Limit) Unlimited() bool {
+	return l.Rate <= 0 || l.Burst <= 0
+}
+
+func (l Limit) interval() time.Duration {
+	return time.Duration(float64(time.Second) / l.Rate)
+}
+
+// cost is how long the bucket takes to refill the tokens a request takes
+func (l Limit) cost() time.Duration {
+	if l.Cost > 1 {
+		return time.Duration(l.Cost) * l.interval()
+	}
+	return l.interval()
+}
+
+func (l Limit) tolerance() time.Duration {
+	return time.Duration(l.Burst) * l.interval()
+}
+
+// Limiter tracks buckets by key. Buckets are stored as the time at which they will be full again (the "theoretical
+// arrival time" of GCRA), which behaves exactly like a token bucket but only needs a single timestamp per key
+type Limiter interface {
+	// Allow takes the request's tokens from the bucket for key, returning how long to wait before retrying if there are none left
+	Allow(ctx context.Context, key string, limit Limit) (bool, time.Duration, error)
+	// Prune forgets buckets which have refilled completely
+	Prune(ctx context.Context) error
+}
+
+// take applies a request to a bucket full at tat, returning the new tat if the request is allowed or the time to wait
+// if it isn't
+func take(now time.Time, tat time.Time, limit Limit) (time.Time, time.Duration, bool) {
+	if tat.Before(now) {
+		tat = now
+	}
+	next := tat.Add(limit.cost())
+	if wait := next.Sub(now) - limit.tolerance(); wait > 0 {
+		return tat, wait, false
+	}
+	return next, 0, true
+}
+
+// IPKey returns the bucket key for a client address. IPv6 clients usually control a whole /64, so they share a bucket
+func IPKey(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil || parsed.To4() != nil {
+		return "ip:" + ip
+	}
+	return "ip:" + parsed.Mask(net.CIDRMask(64, 128)).String() + "/64"
+}
@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func Test_MemoryLimiter(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	m := NewMemoryLimiter()
+	m.now = func() time.Time { return now }
+
+	limit := Limit{Rate: 2, Burst: 3}
+
+	for i := 0; i < 3; i++ {
+		ok, _, err := m.Allow(context.Background(), "a", limit)
+		assert.NoError(t, err)
+		assert.True(t, ok, "burst request %d", i)
+	}
+
+	ok, wait, err := m.Allow(context.Background(), "a", limit)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, wait)
+
+	// other keys have their own bucket
+	ok, _, _ = m.Allow(context.Background(), "b", limit)
+	assert.True(t, ok)
+
+	now = now.Add(wait)
+	ok, _, _ = m.Allow(context.Background(), "a", limit)
+	assert.True(t, ok)
+	ok, _, _ = m.Allow(context.Background(), "a", limit)
+	assert.False(t, ok)
+
+	// once full again, buckets are forgotten
+	now = now.Add(2 * time.Second)
+	assert.NoError(t, m.Prune(context.Background()))
+	assert.Empty(t, m.buckets)
+
+	for i := 0; i < 100; i++ {
+		ok, _, _ = m.Allow(context.Background(), "c", Limit{})
+		assert.True(t, ok)
+	}
+
+	// expensive requests take several tokens at once
+	ok, _, _ = m.Allow(context.Background(), "d", Limit{Rate: 2, Burst: 3, Cost: 2})
+	assert.True(t, ok)
+	ok, wait, _ = m.Allow(context.Background(), "d", Limit{Rate: 2, Burst: 3, Cost: 2})
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, wait)
+	ok, _, _ = m.Allow(context.Background(), "d", limit)
+	assert.True(t, ok)
+}
+
+func Test_IPKey(t *testing.T) {
+	assert.Equal(t, "ip:1.2.3.4", IPKey("1.2.3.4"))
+	assert.Equal(t, "ip:2001:db8:1:2::/64", IPKey("2001:db8:1:2:3:4:5:6"))
+	assert.Equal(t, IPKey("2001:db8:1:2::1"), IPKey("2001:db8:1:2:ffff::"))
+}
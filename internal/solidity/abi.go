@@ -130,7 +130,7 @@ func DecodeFunctionSignature(sig string) (*abi.Method, error) {
 
 	v, err := abi.JSON(bytes.NewReader(b))
 	if err != nil {
-		panic(fmt.Errorf("failed to decode %s: %w", sig, err))
+		return nil, fmt.Errorf("failed to decode %s: %w", sig, err)
 	}
 
 	e := v.Methods[name]
@@ -189,7 +189,7 @@ func DecodeErrorSignature(sig string) (*abi.Error, error) {
 
 	v, err := abi.JSON(bytes.NewReader(b))
 	if err != nil {
-		panic(fmt.Errorf("failed to decode %s: %w", sig, err))
+		return nil, fmt.Errorf("failed to decode %s: %w", sig, err)
 	}
 
 	e := v.Errors[name]
@@ -249,13 +249,15 @@ func DecodeEventSignature(sig string) (*abi.Event, error) {
 
 	v, err := abi.JSON(bytes.NewReader(b))
 	if err != nil {
-		panic(fmt.Errorf("failed to decode %s: %w", sig, err))
+		return nil, fmt.Errorf("failed to decode %s: %w", sig, err)
 	}
 
 	e := v.Events[name]
 	return &e, nil
 }
 
+var tupleArraySuffix = regexp.MustCompile(`^(\[[0-9]*\])*`)
+
 func rewriteTuple(params string) ([]abi.ArgumentMarshaling, error) {
 	if params[0] != '(' {
 		return nil, fmt.Errorf("expected open bracket")
@@ -281,6 +283,10 @@ func rewriteTuple(params string) ([]abi.ArgumentMarshaling, error) {
 
 			params = params[end+1:]
 
+			// arrays of tuples carry their dimensions after the closing bracket
+			typ := "tuple" + tupleArraySuffix.FindString(params)
+			params = params[len(typ)-len("tuple"):]
+
 			nextComma := strings.Index(params, ",")
 
 			var variable string
@@ -298,13 +304,13 @@ func rewriteTuple(params string) ([]abi.ArgumentMarshaling, error) {
 			case 1:
 				rewritten = abi.ArgumentMarshaling{
 					Name:       parts[0],
-					Type:       "tuple",
+					Type:       typ,
 					Components: args,
 				}
 			case 2:
 				rewritten = abi.ArgumentMarshaling{
 					Name:       parts[1],
-					Type:       "tuple",
+					Type:       typ,
 					Components: args,
 					Indexed:    true,
 				}
@@ -0,0 +1,224 @@
+package solidity
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"math/big"
+	"reflect"
+)
+
+// DecodedValue is a decoded argument in a form which marshals to readable json. Integers are decimal strings, bytes
+// and addresses are hex, arrays are lists and tuples are lists of DecodedValue.
+type DecodedValue struct {
+	Type  string `json:"type"`
+	Value any    `json:"value"`
+
+	// Indexed is set for event parameters found in a topic. Indexed values of dynamic types are stored as the hash of
+	// their encoding, which is all that's returned for them, and Hashed is set
+	Indexed bool `json:"indexed,omitempty"`
+	Hashed  bool `json:"hashed,omitempty"`
+}
+
+// DecodeCalldata trial-decodes calldata as a call to the given function signature. Decoding is strict: the arguments
+// must re-encode to exactly the calldata, so that signatures which merely happen to parse are rejected.
+func DecodeCalldata(sig string, data []byte) ([]*DecodedValue, error) {
+	method, err := DecodeFunctionSignature(sig)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < 4 || !bytes.Equal(data[:4], method.ID) {
+		return nil, fmt.Errorf("selector does not match")
+	}
+
+	return decodeStrict(method.Inputs, data[4:])
+}
+
+// DecodeRevert trial-decodes revert data as the given custom error signature, with the same strictness as calldata
+func DecodeRevert(sig string, data []byte) ([]*DecodedValue, error) {
+	abiError, err := DecodeErrorSignature(sig)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < 4 || !bytes.Equal(data[:4], abiError.ID[:4]) {
+		return nil, fmt.Errorf("selector does not match")
+	}
+
+	return decodeStrict(abiError.Inputs, data[4:])
+}
+
+// maximum number of ways of choosing the indexed parameters of an event to try
+const maxIndexedCombinations = 1024
+
+// DecodeLog trial-decodes a non-anonymous log as the given event signature. Signatures don't record which parameters
+// are indexed, so every choice of parameters matching the number of topics is tried in order, and the first which
+// decodes strictly is returned. This prefers indexing the leading parameters, as Solidity developers usually do.
+func DecodeLog(sig string, topics []common.Hash, data []byte) ([]*DecodedValue, error) {
+	event, err := DecodeEventSignature(sig)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(topics) == 0 || topics[0] != event.ID {
+		return nil, fmt.Errorf("topic does not match")
+	}
+
+	indexed := len(topics) - 1
+	if indexed > len(event.Inputs) {
+		return nil, fmt.Errorf("too many topics")
+	}
+
+	var (
+		result   []*DecodedValue
+		attempts int
+	)
+	combinations(len(event.Inputs), indexed, func(positions []int) bool {
+		attempts++
+		result, err = decodeLogWith(event.Inputs, positions, topics[1:], data)
+		return err == nil || attempts >= maxIndexedCombinations
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func decodeLogWith(inputs abi.Arguments, positions []int, topics []common.Hash, data []byte) ([]*DecodedValue, error) {
+	isIndexed := make(map[int]common.Hash)
+	for i, position := range positions {
+		isIndexed[position] = topics[i]
+	}
+
+	var nonIndexed abi.Arguments
+	for i, input := range inputs {
+		if _, ok := isIndexed[i]; !ok {
+			// unpacking skips arguments marked as indexed, which a declaration might have
+			input.Indexed = false
+			nonIndexed = append(nonIndexed, input)
+		}
+	}
+
+	decodedData, err := decodeStrict(nonIndexed, data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*DecodedValue, len(inputs))
+	for i, input := range inputs {
+		topic, ok := isIndexed[i]
+		if !ok {
+			result[i], decodedData = decodedData[0], decodedData[1:]
+			continue
+		}
+
+		switch input.Type.T {
+		case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+			result[i] = &DecodedValue{
+				Type:    input.Type.String(),
+				Value:   topic.Hex(),
+				Indexed: true,
+				Hashed:  true,
+			}
+		default:
+			decoded, err := decodeStrict(abi.Arguments{{Type: input.Type}}, topic.Bytes())
+			if err != nil {
+				return nil, err
+			}
+			result[i] = decoded[0]
+			result[i].Indexed = true
+		}
+	}
+
+	return result, nil
+}
+
+// combinations calls f with every way of choosing k of n positions in lexicographic order, until f returns true
+func combinations(n int, k int, f func([]int) bool) {
+	positions := make([]int, k)
+	for i := range positions {
+		positions[i] = i
+	}
+
+	for {
+		if f(positions) {
+			return
+		}
+
+		i := k - 1
+		for i >= 0 && positions[i] == n-k+i {
+			i--
+		}
+		if i < 0 {
+			return
+		}
+
+		positions[i]++
+		for j := i + 1; j < k; j++ {
+			positions[j] = positions[j-1] + 1
+		}
+	}
+}
+
+// decodeStrict unpacks data and checks that it's the canonical encoding of the values found
+func decodeStrict(args abi.Arguments, data []byte) ([]*DecodedValue, error) {
+	values, err := args.Unpack(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack: %w", err)
+	}
+
+	encoded, err := args.Pack(values...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to repack: %w", err)
+	}
+	if !bytes.Equal(encoded, data) {
+		return nil, fmt.Errorf("data is not canonically encoded")
+	}
+
+	result := make([]*DecodedValue, len(args))
+	for i, arg := range args {
+		result[i] = &DecodedValue{
+			Type:  arg.Type.String(),
+			Value: formatValue(arg.Type, reflect.ValueOf(values[i])),
+		}
+	}
+	return result, nil
+}
+
+func formatValue(typ abi.Type, value reflect.Value) any {
+	switch typ.T {
+	case abi.IntTy, abi.UintTy:
+		if v, ok := value.Interface().(*big.Int); ok {
+			return v.String()
+		}
+		return fmt.Sprint(value.Interface())
+	case abi.AddressTy:
+		return value.Interface().(common.Address).Hex()
+	case abi.BytesTy:
+		return hexutil.Encode(value.Bytes())
+	case abi.FixedBytesTy, abi.FunctionTy:
+		b := make([]byte, value.Len())
+		reflect.Copy(reflect.ValueOf(b), value)
+		return hexutil.Encode(b)
+	case abi.SliceTy, abi.ArrayTy:
+		result := make([]any, value.Len())
+		for i := range result {
+			result[i] = formatValue(*typ.Elem, value.Index(i))
+		}
+		return result
+	case abi.TupleTy:
+		result := make([]*DecodedValue, len(typ.TupleElems))
+		for i, elem := range typ.TupleElems {
+			result[i] = &DecodedValue{
+				Type:  elem.String(),
+				Value: formatValue(*elem, value.Field(i)),
+			}
+		}
+		return result
+	default:
+		return value.Interface()
+	}
+}
@@ -0,0 +1,127 @@
+package solidity
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_DecodeCalldata(t *testing.T) {
+	transfer := hexutil.MustDecode("0xa9059cbb" +
+		"000000000000000000000000d8da6bf26964af9d7eed9e10e9b0d9a1a7c9f1a2" +
+		"00000000000000000000000000000000000000000000000000000000000003e8")
+
+	decoded, err := DecodeCalldata("transfer(address,uint256)", transfer)
+	assert.NoError(t, err)
+	assert.Equal(t, []*DecodedValue{
+		{Type: "address", Value: "0xd8Da6bF26964af9d7EeD9e10e9b0D9a1a7c9F1A2"},
+		{Type: "uint256", Value: "1000"},
+	}, decoded)
+
+	_, err = DecodeCalldata("approve(address,uint256)", transfer)
+	assert.Error(t, err, "selector mismatch")
+
+	_, err = DecodeCalldata("transfer(address,uint256)", transfer[:36])
+	assert.Error(t, err, "truncated")
+
+	// an address with dirty upper bits unpacks fine, but isn't what a compiler would have produced
+	dirty := append([]byte{}, transfer...)
+	dirty[4] = 0xff
+	_, err = DecodeCalldata("transfer(address,uint256)", dirty)
+	assert.Error(t, err, "dirty address")
+
+	// f((uint8,bytes4)[],string) with a single tuple and "hi"
+	sig := "f((uint8,bytes4)[],string)"
+	method, err := DecodeFunctionSignature(sig)
+	assert.NoError(t, err)
+	calldata := append(append([]byte{}, method.ID...), hexutil.MustDecode("0x"+
+		"0000000000000000000000000000000000000000000000000000000000000040"+
+		"00000000000000000000000000000000000000000000000000000000000000a0"+
+		"0000000000000000000000000000000000000000000000000000000000000001"+
+		"0000000000000000000000000000000000000000000000000000000000000007"+
+		"deadbeef00000000000000000000000000000000000000000000000000000000"+
+		"0000000000000000000000000000000000000000000000000000000000000002"+
+		"6869000000000000000000000000000000000000000000000000000000000000")...)
+
+	decoded, err = DecodeCalldata(sig, calldata)
+	assert.NoError(t, err)
+	assert.Equal(t, []*DecodedValue{
+		{Type: "(uint8,bytes4)[]", Value: []any{
+			[]*DecodedValue{
+				{Type: "uint8", Value: "7"},
+				{Type: "bytes4", Value: "0xdeadbeef"},
+			},
+		}},
+		{Type: "string", Value: "hi"},
+	}, decoded)
+}
+
+func Test_DecodeRevert(t *testing.T) {
+	abiError, err := DecodeErrorSignature("InsufficientBalance(uint256,uint256)")
+	assert.NoError(t, err)
+
+	data := append(append([]byte{}, abiError.ID[:4]...), hexutil.MustDecode("0x"+
+		"0000000000000000000000000000000000000000000000000000000000000001"+
+		"0000000000000000000000000000000000000000000000000000000000000002")...)
+
+	decoded, err := DecodeRevert("InsufficientBalance(uint256,uint256)", data)
+	assert.NoError(t, err)
+	assert.Equal(t, []*DecodedValue{
+		{Type: "uint256", Value: "1"},
+		{Type: "uint256", Value: "2"},
+	}, decoded)
+
+	_, err = DecodeRevert("InsufficientBalance(uint256)", data)
+	assert.Error(t, err)
+}
+
+func Test_DecodeLog(t *testing.T) {
+	transfer := common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+	from := common.HexToHash("0x000000000000000000000000d8da6bf26964af9d7eed9e10e9b0d9a1a7c9f1a2")
+	to := common.HexToHash("0x000000000000000000000000000000000000000000000000000000000000dead")
+	amount := hexutil.MustDecode("0x00000000000000000000000000000000000000000000000000000000000003e8")
+
+	// erc20 transfers index the addresses
+	decoded, err := DecodeLog("Transfer(address,address,uint256)", []common.Hash{transfer, from, to}, amount)
+	assert.NoError(t, err)
+	assert.Equal(t, []*DecodedValue{
+		{Type: "address", Value: "0xd8Da6bF26964af9d7EeD9e10e9b0D9a1a7c9F1A2", Indexed: true},
+		{Type: "address", Value: "0x000000000000000000000000000000000000dEaD", Indexed: true},
+		{Type: "uint256", Value: "1000"},
+	}, decoded)
+
+	// erc721 transfers index everything
+	decoded, err = DecodeLog("Transfer(address,address,uint256)", []common.Hash{transfer, from, to, common.BytesToHash(amount)}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "1000", decoded[2].Value)
+	assert.True(t, decoded[2].Indexed)
+
+	// a huge amount can't be an address, so the only way to read this is with the second address in the data
+	huge := common.HexToHash("0x8000000000000000000000000000000000000000000000000000000000000000")
+	decoded, err = DecodeLog("Transfer(address,address,uint256)", []common.Hash{transfer, from, huge}, to.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, []*DecodedValue{
+		{Type: "address", Value: "0xd8Da6bF26964af9d7EeD9e10e9b0D9a1a7c9F1A2", Indexed: true},
+		{Type: "address", Value: "0x000000000000000000000000000000000000dEaD"},
+		{Type: "uint256", Value: "57896044618658097711785492504343953926634992332820282019728792003956564819968", Indexed: true},
+	}, decoded)
+
+	_, err = DecodeLog("Approval(address,address,uint256)", []common.Hash{transfer, from, to}, amount)
+	assert.Error(t, err, "wrong topic")
+
+	_, err = DecodeLog("Transfer(address,address,uint256)", []common.Hash{transfer, from, to, from, to}, nil)
+	assert.Error(t, err, "too many topics")
+
+	_, err = DecodeLog("Transfer(address,address,uint256)", []common.Hash{transfer, from, to}, nil)
+	assert.Error(t, err, "missing data")
+
+	// dynamic values are only available as their hash when indexed
+	event, err := DecodeEventSignature("Named(string)")
+	assert.NoError(t, err)
+	decoded, err = DecodeLog("Named(string)", []common.Hash{event.ID, from}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []*DecodedValue{
+		{Type: "string", Value: from.Hex(), Indexed: true, Hashed: true},
+	}, decoded)
+}
@@ -0,0 +1,142 @@
+package solidity
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var declarationRe = regexp.MustCompile(`^\s*(?:(function|event|error)\s+)?([a-zA-Z$_][a-zA-Z0-9$_]*)\s*\(`)
+
+// NormalizeSignature turns a human-written declaration such as
+//
+//	function transfer(address to, uint amount) external returns (bool)
+//
+// into its canonical signature, transfer(address,uint256). Parameter names, data locations, indexed markers and
+// anything after the parameter list are dropped. The kind is the leading keyword, if there was one.
+func NormalizeSignature(decl string) (sig string, kind string, err error) {
+	match := declarationRe.FindStringSubmatchIndex(decl)
+	if match == nil {
+		return "", "", fmt.Errorf("expected a name followed by parameters")
+	}
+	if match[2] != -1 {
+		kind = decl[match[2]:match[3]]
+	}
+	name := decl[match[4]:match[5]]
+	switch name {
+	case "constructor", "fallback", "receive":
+		return "", "", fmt.Errorf("%s has no signature", name)
+	}
+
+	// everything after the closing bracket is modifiers, return values or a body, none of which affect the signature
+	rest := decl[match[1]-1:]
+	end := findClosingBracket(rest, '(', ')')
+	if end == -1 {
+		return "", "", fmt.Errorf("signature is not balanced")
+	}
+
+	params, err := normalizeParams(rest[1:end])
+	if err != nil {
+		return "", "", err
+	}
+
+	sig = name + "(" + params + ")"
+	if !VerifySignature(sig) {
+		return "", "", fmt.Errorf("normalized signature %s is not valid", sig)
+	}
+	return sig, kind, nil
+}
+
+func normalizeParams(params string) (string, error) {
+	if strings.TrimSpace(params) == "" {
+		return "", nil
+	}
+
+	var normalized []string
+	for _, param := range splitParams(params) {
+		typ, err := normalizeParam(strings.TrimSpace(param))
+		if err != nil {
+			return "", err
+		}
+		normalized = append(normalized, typ)
+	}
+	return strings.Join(normalized, ","), nil
+}
+
+// splitParams splits on the commas which aren't nested inside a tuple or array
+func splitParams(params string) []string {
+	var (
+		parts []string
+		depth int
+		start int
+	)
+	for i, c := range params {
+		switch c {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, params[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, params[start:])
+}
+
+var arraySuffixRe = regexp.MustCompile(`^(\s*\[\s*[0-9]*\s*\])*`)
+
+func normalizeParam(param string) (string, error) {
+	if param == "" {
+		return "", fmt.Errorf("empty parameter")
+	}
+
+	var typ string
+	if strings.HasPrefix(param, "tuple(") {
+		param = param[len("tuple"):]
+	}
+	if param[0] == '(' {
+		end := findClosingBracket(param, '(', ')')
+		if end == -1 {
+			return "", fmt.Errorf("tuple is not balanced")
+		}
+		components, err := normalizeParams(param[1:end])
+		if err != nil {
+			return "", err
+		}
+		typ = "(" + components + ")"
+		param = param[end+1:]
+	} else {
+		end := strings.IndexAny(param, " \t\n[")
+		if end == -1 {
+			end = len(param)
+		}
+		typ = normalizeElementaryType(param[:end])
+		param = param[end:]
+	}
+
+	// whatever follows the array dimensions is a name, data location or indexed marker
+	suffix := arraySuffixRe.FindString(param)
+	typ += strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\t' || r == '\n' {
+			return -1
+		}
+		return r
+	}, suffix)
+
+	return typ, nil
+}
+
+func normalizeElementaryType(typ string) string {
+	switch typ {
+	case "uint":
+		return "uint256"
+	case "int":
+		return "int256"
+	case "byte":
+		return "bytes1"
+	}
+	return typ
+}
@@ -0,0 +1,41 @@
+package solidity
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_NormalizeSignature(t *testing.T) {
+	for _, tc := range []struct {
+		decl string
+		sig  string
+		kind string
+	}{
+		{`transfer(address,uint256)`, `transfer(address,uint256)`, ""},
+		{`function transfer(address to, uint amount) external returns (bool)`, `transfer(address,uint256)`, "function"},
+		{`function  swap ( uint[] calldata amounts , bytes memory data ) public payable { }`, `swap(uint256[],bytes)`, "function"},
+		{`event Transfer(address indexed from, address indexed to, uint value);`, `Transfer(address,address,uint256)`, "event"},
+		{`error InsufficientBalance(uint256 available, uint256 required)`, `InsufficientBalance(uint256,uint256)`, "error"},
+		{`function f((uint a, (address b, int[2] c)[] d) memory s, address payable to)`, `f((uint256,(address,int256[2])[]),address)`, "function"},
+		{`function f(tuple(uint a, bool b)[3] calldata s, byte x)`, `f((uint256,bool)[3],bytes1)`, "function"},
+		{`function f(uint [ 2 ] [] x)`, `f(uint256[2][])`, "function"},
+		{`function noArgs() view`, `noArgs()`, "function"},
+	} {
+		sig, kind, err := NormalizeSignature(tc.decl)
+		assert.NoError(t, err, tc.decl)
+		assert.Equal(t, tc.sig, sig, tc.decl)
+		assert.Equal(t, tc.kind, kind, tc.decl)
+	}
+
+	for _, decl := range []string{
+		``,
+		`constructor(address owner)`,
+		`function f(IERC20 token)`,
+		`function f(uint a`,
+		`function f(uint a,)`,
+		`function f(mapping(address => uint) storage m)`,
+	} {
+		_, _, err := NormalizeSignature(decl)
+		assert.Error(t, err, decl)
+	}
+}
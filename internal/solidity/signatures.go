@@ -0,0 +1,43 @@
+package solidity
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+type ABISignatures struct {
+	Functions []string
+	Events    []string
+	Errors    []string
+}
+
+// ExtractSignatures parses a JSON ABI and returns the canonical signature of every function, event and error in it.
+// Entries are parsed one at a time so that overloaded errors and events aren't collapsed by name.
+func ExtractSignatures(data []byte) (*ABISignatures, error) {
+	var entries []json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal abi: %w", err)
+	}
+
+	result := &ABISignatures{}
+	for idx, entry := range entries {
+		parsed, err := abi.JSON(bytes.NewReader([]byte("[" + string(entry) + "]")))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse abi entry %d: %w", idx, err)
+		}
+
+		for _, method := range parsed.Methods {
+			result.Functions = append(result.Functions, method.Sig)
+		}
+		for _, event := range parsed.Events {
+			result.Events = append(result.Events, event.Sig)
+		}
+		for _, abiError := range parsed.Errors {
+			result.Errors = append(result.Errors, abiError.Sig)
+		}
+	}
+
+	return result, nil
+}
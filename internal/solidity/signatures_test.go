@@ -0,0 +1,36 @@
+package solidity
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_ExtractSignatures(t *testing.T) {
+	abiJSON := `[
+		{"type":"constructor","inputs":[{"name":"owner","type":"address"}],"stateMutability":"nonpayable"},
+		{"type":"fallback","stateMutability":"payable"},
+		{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}],"stateMutability":"nonpayable"},
+		{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"}],"outputs":[],"stateMutability":"nonpayable"},
+		{"type":"function","name":"submit","inputs":[{"name":"orders","type":"tuple[]","components":[{"name":"maker","type":"address"},{"name":"legs","type":"tuple[2]","components":[{"name":"token","type":"address"},{"name":"amount","type":"uint128"}]}]},{"name":"data","type":"bytes"}],"outputs":[],"stateMutability":"nonpayable"},
+		{"type":"event","name":"Transfer","inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}],"anonymous":false},
+		{"type":"error","name":"InsufficientBalance","inputs":[{"name":"available","type":"uint256"}]},
+		{"type":"error","name":"InsufficientBalance","inputs":[{"name":"available","type":"uint256"},{"name":"required","type":"uint256"}]}
+	]`
+
+	sigs, err := ExtractSignatures([]byte(abiJSON))
+	assert.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{
+		`transfer(address,uint256)`,
+		`transfer(address)`,
+		`submit((address,(address,uint128)[2])[],bytes)`,
+	}, sigs.Functions)
+	assert.ElementsMatch(t, []string{`Transfer(address,address,uint256)`}, sigs.Events)
+	assert.ElementsMatch(t, []string{
+		`InsufficientBalance(uint256)`,
+		`InsufficientBalance(uint256,uint256)`,
+	}, sigs.Errors)
+
+	_, err = ExtractSignatures([]byte(`{"type":"function"}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,113 @@
+package signature_database_srv
+
+import (
+	"errors"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/auth"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/core"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/client"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/database"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"time"
+)
+
+// auditReportCacheTime is how long stats reuse the latest audit report, which another replica may have replaced since
+const auditReportCacheTime = time.Minute
+
+// runAudits audits on every replica's schedule, but only one replica audits per interval. Audits are skipped if one
+// started within half the interval, so that this replica's ticker firing a little early doesn't skip its own turn
+func (s *Service) runAudits() {
+	ticker := time.NewTicker(s.config.AuditInterval)
+	for ; true; <-ticker.C {
+		_, err := s.audit(client.AuditMode(s.config.AuditMode), s.config.AuditInterval/2)
+		if errors.Is(err, database.ErrAuditRunning) || errors.Is(err, database.ErrAuditNotDue) {
+			log.WithError(err).Infof("skipping audit")
+		} else if err != nil {
+			log.WithError(err).Errorf("failed to audit signatures")
+		}
+	}
+}
+
+// audit checks every stored signature, refusing to run alongside another audit since the table scans are expensive,
+// and brings the export up to date if anything was fixed
+func (s *Service) audit(mode client.AuditMode, interval time.Duration) (*client.AuditReport, error) {
+	report, err := s.db.AuditSignatures(mode, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, fixed := range report.Fixed {
+		if fixed > 0 {
+			go s.refreshExport()
+			break
+		}
+	}
+
+	s.auditReportLock.Lock()
+	s.auditReport, s.auditReportLoaded = report, time.Now()
+	s.auditReportLock.Unlock()
+
+	fields := log.Fields{
+		"mode": mode,
+		"took": report.FinishedAt.Sub(report.StartedAt),
+	}
+	for _, typ := range client.SignatureTypes() {
+		fields["checked_"+string(typ)] = report.Checked[typ]
+		fields["mismatched_"+string(typ)] = report.Mismatched[typ]
+		fields["fixed_"+string(typ)] = report.Fixed[typ]
+	}
+	if len(report.Mismatches) > 0 {
+		log.WithFields(fields).Warnf("audit found mismatched signatures")
+	} else {
+		log.WithFields(fields).Infof("audit found no mismatched signatures")
+	}
+
+	return report, nil
+}
+
+// latestAuditReport returns the report of the most recent audit, only going to the database once the cached one is a
+// while old, as it's included in every stats response
+func (s *Service) latestAuditReport() (*client.AuditReport, error) {
+	s.auditReportLock.Lock()
+	defer s.auditReportLock.Unlock()
+
+	if time.Since(s.auditReportLoaded) < auditReportCacheTime {
+		return s.auditReport, nil
+	}
+
+	report, err := s.db.LatestAuditReport()
+	if err != nil {
+		return nil, err
+	}
+	s.auditReport, s.auditReportLoaded = report, time.Now()
+
+	return report, nil
+}
+
+func (s *Service) serveAudit(w http.ResponseWriter, r *http.Request) {
+	mode := client.AuditMode(s.config.AuditMode)
+	if r.URL.Query().Has("mode") {
+		mode = client.AuditMode(r.URL.Query().Get("mode"))
+		if !mode.Valid() {
+			fail(w, http.StatusBadRequest, nil, "mode must be one of flag, repair or quarantine")
+			return
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"ip":   core.GetRemoteIP(r),
+		"by":   auth.KeyName(r.Context()),
+		"mode": mode,
+	}).Infof("running audit")
+
+	report, err := s.audit(mode, 0)
+	if errors.Is(err, database.ErrAuditRunning) {
+		fail(w, http.StatusConflict, err, err.Error())
+		return
+	} else if err != nil {
+		fail(w, http.StatusInternalServerError, err, "failed to audit signatures")
+		return
+	}
+
+	succeed(w, report)
+}
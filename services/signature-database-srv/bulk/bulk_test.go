@@ -0,0 +1,166 @@
+package bulk
+
+import (
+	"errors"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/client"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/database"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+)
+
+func readAll(t *testing.T, input string, format Format) ([]*Entry, int, error) {
+	r, err := NewReader(strings.NewReader(input), format, client.SignatureTypeFunction)
+	assert.NoError(t, err)
+
+	var (
+		entries   []*Entry
+		malformed int
+	)
+	for {
+		entry, err := r.Next()
+		if err == io.EOF {
+			return entries, malformed, nil
+		} else if errors.Is(err, ErrMalformed) {
+			malformed++
+			continue
+		} else if err != nil {
+			return entries, malformed, err
+		}
+		entries = append(entries, entry)
+	}
+}
+
+func Test_Reader(t *testing.T) {
+	transfer := &Entry{Type: client.SignatureTypeFunction, Hash: "0xa9059cbb", Name: "transfer(address,uint256)"}
+	event := &Entry{Type: client.SignatureTypeEvent, Hash: "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef", Name: "Transfer(address,address,uint256)"}
+
+	strip := func(entries []*Entry) []*Entry {
+		for _, entry := range entries {
+			entry.Line = 0
+		}
+		return entries
+	}
+
+	for _, tc := range []struct {
+		format    Format
+		input     string
+		entries   []*Entry
+		malformed int
+	}{
+		{
+			FormatText,
+			"0xa9059cbb,transfer(address,uint256)\n\n0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef,Transfer(address,address,uint256)\ngarbage\n",
+			[]*Entry{transfer, event},
+			1,
+		},
+		{
+			FormatCSV,
+			"id,type,hash,name\n1,function,0xa9059cbb,\"transfer(address,uint256)\"\n2,event,0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef,\"Transfer(address,address,uint256)\"\n3,nope,0x00,x\n#end,3,,\n",
+			[]*Entry{transfer, event},
+			1,
+		},
+		{
+			FormatNDJSON,
+			`{"id":1,"type":"function","hash":"0xa9059cbb","name":"transfer(address,uint256)"}` + "\n{oops\n" +
+				`{"id":2,"type":"event","hash":"0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef","name":"Transfer(address,address,uint256)"}` + "\n" +
+				`{"end":true,"count":3}`,
+			[]*Entry{transfer, event},
+			1,
+		},
+		{
+			FormatLines,
+			"transfer(address,uint256)\n  \n",
+			[]*Entry{{Type: client.SignatureTypeFunction, Name: "transfer(address,uint256)"}},
+			0,
+		},
+		{
+			Format4Byte,
+			`{"count":2,"next":null,"results":[{"id":1,"text_signature":"transfer(address,uint256)","hex_signature":"0xa9059cbb"}]}
+			{"count":2,"results":null}
+			[{"id":2,"text_signature":"Transfer(address,address,uint256)","hex_signature":"0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"},{"id":3}]`,
+			[]*Entry{transfer, event},
+			1,
+		},
+	} {
+		entries, malformed, err := readAll(t, tc.input, tc.format)
+		assert.NoError(t, err, tc.format)
+		assert.Equal(t, tc.entries, strip(entries), tc.format)
+		assert.Equal(t, tc.malformed, malformed, tc.format)
+	}
+}
+
+func Test_ReaderExport(t *testing.T) {
+	// as served by /v1/export
+	exports := map[Format]string{
+		FormatCSV: "id,type,hash,name\n2,event,0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef,\"Transfer(address,address,uint256)\"\n#end,1,,\n",
+		FormatNDJSON: `{"id":2,"type":"event","hash":"0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef","name":"Transfer(address,address,uint256)"}` + "\n" +
+			`{"end":true,"count":1}` + "\n",
+	}
+
+	for format, export := range exports {
+		entries, malformed, err := readAll(t, export, format)
+		assert.NoError(t, err, format)
+		assert.Len(t, entries, 1, format)
+		assert.Equal(t, 0, malformed, format)
+
+		// cut short before the trailer
+		trailer := strings.LastIndex(strings.TrimSuffix(export, "\n"), "\n") + 1
+		_, _, err = readAll(t, export[:trailer], format)
+		var truncated *client.TruncatedExportError
+		assert.ErrorAs(t, err, &truncated, format)
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF, format)
+
+		// a trailer which doesn't match what was read
+		_, _, err = readAll(t, export[:trailer]+strings.Replace(export[trailer:], "1", "2", 1), format)
+		assert.ErrorContains(t, err, "counts 2 signatures but 1 were read", format)
+	}
+}
+
+type memoryStore struct {
+	saved map[string]bool
+	calls int
+}
+
+func (m *memoryStore) SaveSignatures(typ client.SignatureType, names []string, meta *database.SignatureMetadata) (*client.ImportResponseDetails, error) {
+	m.calls++
+	res := client.NewImportResponseDetails()
+	for _, name := range names {
+		if m.saved[name] {
+			res.Duplicated[name] = ""
+		} else {
+			m.saved[name] = true
+			res.Imported[name] = ""
+		}
+	}
+	return res, nil
+}
+
+func Test_Importer(t *testing.T) {
+	store := &memoryStore{saved: map[string]bool{"approve(address,uint256)": true}}
+
+	var invalid []error
+	importer := NewImporter(store, &database.SignatureMetadata{Source: client.SignatureSourceImport}, 2)
+	importer.OnInvalid = func(err error) {
+		invalid = append(invalid, err)
+	}
+
+	r, err := NewReader(strings.NewReader(strings.Join([]string{
+		"0xa9059cbb,transfer(address,uint256)",
+		"0x095ea7b3,approve(address,uint256)",
+		"0x12345678,balanceOf(address)",
+		"0x18160ddd,totalSupply()",
+		"0x313ce567,decimals(",
+		"0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef,Transfer(address,address,uint256)",
+	}, "\n")), FormatText, client.SignatureTypeFunction)
+	assert.NoError(t, err)
+
+	stats, err := importer.Import(r)
+	assert.NoError(t, err)
+	assert.Equal(t, Stats{Read: 6, Imported: 3, Duplicated: 1, Invalid: 2}, stats)
+	assert.Len(t, invalid, 2)
+	assert.Contains(t, invalid[0].Error(), "line 3")
+	// one full batch of functions, then what was left of each type
+	assert.Equal(t, 3, store.calls)
+}
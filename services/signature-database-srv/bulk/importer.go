@@ -0,0 +1,128 @@
+package bulk
+
+import (
+	"errors"
+	"fmt"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/solidity"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/client"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/database"
+	log "github.com/sirupsen/logrus"
+	"io"
+	"time"
+)
+
+// Store is the part of the database which the importer needs
+type Store interface {
+	SaveSignatures(typ client.SignatureType, names []string, meta *database.SignatureMetadata) (*client.ImportResponseDetails, error)
+}
+
+type Stats struct {
+	Read       int
+	Imported   int
+	Duplicated int
+	Invalid    int
+}
+
+// Importer saves entries in batches, each of which is a single ExecBatch round trip
+type Importer struct {
+	store     Store
+	meta      *database.SignatureMetadata
+	batchSize int
+
+	// OnInvalid is called for every entry which is malformed or fails verification
+	OnInvalid func(err error)
+	// OnProgress is called at most every ProgressInterval while importing
+	OnProgress       func(stats Stats)
+	ProgressInterval time.Duration
+
+	pending map[client.SignatureType][]string
+	stats   Stats
+}
+
+func NewImporter(store Store, meta *database.SignatureMetadata, batchSize int) *Importer {
+	return &Importer{
+		store:     store,
+		meta:      meta,
+		batchSize: batchSize,
+
+		OnInvalid: func(err error) {
+			log.WithError(err).Warnf("skipping invalid entry")
+		},
+		OnProgress:       func(stats Stats) {},
+		ProgressInterval: 5 * time.Second,
+
+		pending: make(map[client.SignatureType][]string),
+	}
+}
+
+// Import reads every entry from r, returning the totals so far. Entries from a previous call are counted too
+func (i *Importer) Import(r Reader) (Stats, error) {
+	lastProgress := time.Now()
+
+	for {
+		entry, err := r.Next()
+		if err == io.EOF {
+			break
+		} else if errors.Is(err, ErrMalformed) {
+			i.stats.Read++
+			i.stats.Invalid++
+			i.OnInvalid(err)
+			continue
+		} else if err != nil {
+			return i.stats, err
+		}
+
+		i.stats.Read++
+
+		// hand-written lists get the same leniency as the import endpoint, but dumps with hashes have to match exactly
+		if entry.Hash == "" && !solidity.VerifySignature(entry.Name) {
+			if sig, _, err := solidity.NormalizeSignature(entry.Name); err == nil {
+				entry.Name = sig
+			}
+		}
+
+		if err := entry.Verify(); err != nil {
+			i.stats.Invalid++
+			i.OnInvalid(fmt.Errorf("line %d: %s: %w", entry.Line, entry.Name, err))
+			continue
+		}
+
+		i.pending[entry.Type] = append(i.pending[entry.Type], entry.Name)
+		if len(i.pending[entry.Type]) >= i.batchSize {
+			if err := i.flush(entry.Type); err != nil {
+				return i.stats, err
+			}
+		}
+
+		if time.Since(lastProgress) >= i.ProgressInterval {
+			i.OnProgress(i.stats)
+			lastProgress = time.Now()
+		}
+	}
+
+	for _, typ := range client.SignatureTypes() {
+		if err := i.flush(typ); err != nil {
+			return i.stats, err
+		}
+	}
+	i.OnProgress(i.stats)
+
+	return i.stats, nil
+}
+
+func (i *Importer) flush(typ client.SignatureType) error {
+	names := i.pending[typ]
+	if len(names) == 0 {
+		return nil
+	}
+
+	res, err := i.store.SaveSignatures(typ, names, i.meta)
+	if err != nil {
+		return fmt.Errorf("failed to save %s signatures: %w", typ, err)
+	}
+	i.stats.Imported += len(res.Imported)
+	i.stats.Duplicated += len(res.Duplicated)
+
+	i.pending[typ] = names[:0]
+	return nil
+}
@@ -0,0 +1,329 @@
+// Package bulk loads signatures from large dump files without building one giant import request
+package bulk
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/solidity"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/client"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type Format string
+
+const (
+	// FormatText is our original "0xhash,name" export
+	FormatText Format = "txt"
+	// FormatCSV is our typed "id,type,hash,name" export, ending with its trailer
+	FormatCSV Format = "csv"
+	// FormatNDJSON is our export with one client.ExportedSignature per line, ending with its trailer
+	FormatNDJSON Format = "ndjson"
+	// FormatLines is one signature per line, with no hashes
+	FormatLines Format = "lines"
+	// Format4Byte is the json served by the 4byte.directory api, either a single page, a stream of pages or a plain
+	// array of results
+	Format4Byte Format = "4byte"
+)
+
+func Formats() []Format {
+	return []Format{FormatText, FormatCSV, FormatNDJSON, FormatLines, Format4Byte}
+}
+
+// ErrMalformed is returned for entries which couldn't be parsed. Reading can continue after it
+var ErrMalformed = errors.New("malformed entry")
+
+// Entry is a signature read from a dump. Hash is empty when the format doesn't include one
+type Entry struct {
+	Line int
+	Type client.SignatureType
+	Hash string
+	Name string
+}
+
+// Verify checks that the name is a valid signature and matches the hash, if there is one
+func (e *Entry) Verify() error {
+	if !solidity.VerifySignature(e.Name) {
+		return fmt.Errorf("invalid signature")
+	}
+	if e.Hash == "" {
+		return nil
+	}
+	if hash := hexutil.Encode(crypto.Keccak256([]byte(e.Name))[:e.Type.HashLength()]); hash != strings.ToLower(e.Hash) {
+		return fmt.Errorf("hash mismatch: expected %s", hash)
+	}
+	return nil
+}
+
+type Reader interface {
+	// Next returns the next entry, io.EOF once there are none left, or an error wrapping ErrMalformed if the entry
+	// couldn't be parsed. Our csv and ndjson exports are checked against their trailer, and return a
+	// client.TruncatedExportError if they end without one
+	Next() (*Entry, error)
+}
+
+// NewReader reads entries in the given format. Formats without a type column use typ, except for the text export
+// where 32 byte hashes can only be events
+func NewReader(r io.Reader, format Format, typ client.SignatureType) (Reader, error) {
+	switch format {
+	case FormatText, FormatLines, FormatNDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		return &lineReader{scanner: scanner, format: format, typ: typ}, nil
+	case FormatCSV:
+		c := csv.NewReader(r)
+		c.FieldsPerRecord = -1
+		return &csvReader{csv: c}, nil
+	case Format4Byte:
+		return &fourByteReader{dec: json.NewDecoder(r), typ: typ}, nil
+	default:
+		return nil, fmt.Errorf("unknown format: %s", format)
+	}
+}
+
+func malformed(line int, format string, args ...any) error {
+	return fmt.Errorf("line %d: %w: %s", line, ErrMalformed, fmt.Sprintf(format, args...))
+}
+
+// exportTrailer tracks the rows read from one of our exports, to check against the trailer which ends it
+type exportTrailer struct {
+	count    int64
+	complete bool
+}
+
+// end checks the trailer, after which there must be nothing more
+func (e *exportTrailer) end(line int, trailer *client.ExportTrailer) error {
+	if err := trailer.Check(e.count); err != nil {
+		return fmt.Errorf("line %d: %w", line, err)
+	}
+	e.complete = true
+	return nil
+}
+
+func (e *exportTrailer) eof() error {
+	if !e.complete {
+		return &client.TruncatedExportError{Count: e.count}
+	}
+	return io.EOF
+}
+
+type lineReader struct {
+	scanner *bufio.Scanner
+	format  Format
+	typ     client.SignatureType
+	line    int
+	trailer exportTrailer
+}
+
+func (l *lineReader) Next() (*Entry, error) {
+	for l.scanner.Scan() {
+		l.line++
+
+		text := strings.TrimSpace(l.scanner.Text())
+		if text == "" {
+			continue
+		}
+		if l.trailer.complete {
+			return nil, fmt.Errorf("line %d: unexpected data after the export trailer", l.line)
+		}
+
+		switch l.format {
+		case FormatLines:
+			return &Entry{Line: l.line, Type: l.typ, Name: text}, nil
+		case FormatNDJSON:
+			trailer, err := client.ParseExportTrailer([]byte(text))
+			if err != nil {
+				return nil, malformed(l.line, "%v", err)
+			} else if trailer != nil {
+				if err := l.trailer.end(l.line, trailer); err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			l.trailer.count++
+			var sig client.ExportedSignature
+			if err := json.Unmarshal([]byte(text), &sig); err != nil {
+				return nil, malformed(l.line, "%v", err)
+			}
+			// incremental exports say which signatures were removed, which there's nothing to import for
+			if sig.Removed {
+				continue
+			}
+			if !sig.Type.Valid() {
+				return nil, malformed(l.line, "invalid type %q", sig.Type)
+			}
+			return &Entry{Line: l.line, Type: sig.Type, Hash: sig.Hash, Name: sig.Name}, nil
+		default:
+			hash, name, ok := strings.Cut(text, ",")
+			if !ok || !strings.HasPrefix(hash, "0x") {
+				return nil, malformed(l.line, "expected 0xhash,name")
+			}
+			typ := l.typ
+			if len(hash) == 2+2*32 {
+				typ = client.SignatureTypeEvent
+			}
+			return &Entry{Line: l.line, Type: typ, Hash: hash, Name: name}, nil
+		}
+	}
+	if err := l.scanner.Err(); err != nil {
+		return nil, err
+	}
+	if l.format == FormatNDJSON {
+		return nil, l.trailer.eof()
+	}
+	return nil, io.EOF
+}
+
+type csvReader struct {
+	csv     *csv.Reader
+	header  bool
+	trailer exportTrailer
+}
+
+func (c *csvReader) Next() (*Entry, error) {
+	for {
+		record, err := c.csv.Read()
+		if err == io.EOF {
+			return nil, c.trailer.eof()
+		} else if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return nil, malformed(parseErr.Line, "%v", parseErr.Err)
+			}
+			return nil, err
+		}
+		line, _ := c.csv.FieldPos(0)
+
+		if !c.header {
+			c.header = true
+			if len(record) > 0 && record[0] == "id" {
+				continue
+			}
+		}
+
+		if c.trailer.complete {
+			return nil, fmt.Errorf("line %d: unexpected data after the export trailer", line)
+		}
+		if len(record) > 0 && record[0] == client.ExportTrailerPrefix {
+			if len(record) < 2 {
+				return nil, malformed(line, "expected %s,count", client.ExportTrailerPrefix)
+			}
+			count, err := strconv.ParseInt(record[1], 10, 64)
+			if err != nil {
+				return nil, malformed(line, "invalid trailer count %q", record[1])
+			}
+			if err := c.trailer.end(line, &client.ExportTrailer{End: true, Count: count}); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		c.trailer.count++
+		if len(record) != 4 {
+			return nil, malformed(line, "expected id,type,hash,name")
+		}
+		typ := client.SignatureType(record[1])
+		if !typ.Valid() {
+			return nil, malformed(line, "invalid type %q", record[1])
+		}
+		return &Entry{Line: line, Type: typ, Hash: record[2], Name: record[3]}, nil
+	}
+}
+
+// fourByteReader streams the results of 4byte.directory api responses, without loading whole pages into memory
+type fourByteReader struct {
+	dec   *json.Decoder
+	typ   client.SignatureType
+	count int
+	// inResults is set while positioned inside a results array
+	inResults bool
+	// inPage is set while positioned inside a page object
+	inPage bool
+}
+
+type fourByteResult struct {
+	TextSignature string `json:"text_signature"`
+	HexSignature  string `json:"hex_signature"`
+}
+
+func (f *fourByteReader) Next() (*Entry, error) {
+	for {
+		if f.inResults {
+			if f.dec.More() {
+				f.count++
+
+				var result fourByteResult
+				if err := f.dec.Decode(&result); err != nil {
+					return nil, fmt.Errorf("failed to decode result %d: %w", f.count, err)
+				}
+				if result.TextSignature == "" {
+					return nil, malformed(f.count, "missing text_signature")
+				}
+
+				typ := f.typ
+				if len(result.HexSignature) == 2+2*32 {
+					typ = client.SignatureTypeEvent
+				}
+				return &Entry{Line: f.count, Type: typ, Hash: result.HexSignature, Name: result.TextSignature}, nil
+			}
+			if _, err := f.dec.Token(); err != nil {
+				return nil, err
+			}
+			f.inResults = false
+			continue
+		}
+
+		if f.inPage {
+			if !f.dec.More() {
+				if _, err := f.dec.Token(); err != nil {
+					return nil, err
+				}
+				f.inPage = false
+				continue
+			}
+
+			key, err := f.dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			if key != "results" {
+				var skip json.RawMessage
+				if err := f.dec.Decode(&skip); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			// empty pages may have null results
+			token, err := f.dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			if token == json.Delim('[') {
+				f.inResults = true
+			} else if token != nil {
+				return nil, fmt.Errorf("unexpected %v, expected an array of results", token)
+			}
+			continue
+		}
+
+		token, err := f.dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch token {
+		case json.Delim('{'):
+			f.inPage = true
+		case json.Delim('['):
+			f.inResults = true
+		default:
+			return nil, fmt.Errorf("unexpected %v, expected a page or an array of results", token)
+		}
+	}
+}
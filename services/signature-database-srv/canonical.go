@@ -0,0 +1,147 @@
+package signature_database_srv
+
+import (
+	"fmt"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/client"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// canonicalRefreshInterval is how often the canonical signatures are refreshed from their sources
+const canonicalRefreshInterval = 24 * time.Hour
+
+type canonicalSignature struct {
+	Signature string `yaml:"signature"`
+	Source    string `yaml:"source"`
+}
+
+// fetchCanonicalSignatures loads a canonical signature list from a url or a local file
+func fetchCanonicalSignatures(source string) (map[string]*canonicalSignature, error) {
+	var body io.ReadCloser
+
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		resp, err := http.Get(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch canonical signatures: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to fetch canonical signatures: http status %d", resp.StatusCode)
+		}
+		body = resp.Body
+	} else {
+		f, err := os.Open(strings.TrimPrefix(source, "file://"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open canonical signatures: %w", err)
+		}
+		body = f
+	}
+	defer body.Close()
+
+	var output map[string]*canonicalSignature
+	if err := yaml.NewDecoder(body).Decode(&output); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal yaml: %w", err)
+	}
+
+	// an empty list is far more likely to be a broken source than a deliberate one, and would prune everything
+	if len(output) == 0 {
+		return nil, fmt.Errorf("no canonical signatures found")
+	}
+
+	return output, nil
+}
+
+// loadCanonicalSignatures refreshes every configured source, layers them with later sources taking precedence, and
+// syncs the result into the database. If a source can't be fetched, the last copy that was successfully fetched is
+// used instead, nothing is pruned, and an error is returned once everything else has been synced. With no sources
+// configured only the manual entries are loaded, and that isn't an error.
+func (s *Service) loadCanonicalSignatures() error {
+	if len(s.config.CanonicalSignatureSources) == 0 {
+		return s.reloadCanonicalSignatures()
+	}
+
+	s.canonicalSignaturesLock.Lock()
+	lastRefresh := s.lastCanonicalSignaturesRefresh
+	s.canonicalSignaturesLock.Unlock()
+
+	if time.Since(lastRefresh) < 1*time.Hour {
+		return fmt.Errorf("refreshing too soon")
+	}
+
+	fetched := make(map[string]map[string]*canonicalSignature)
+	var failed []string
+	for _, source := range s.config.CanonicalSignatureSources {
+		sigs, err := fetchCanonicalSignatures(source)
+		if err != nil {
+			log.WithError(err).WithField("source", source).Warnf("failed to refresh canonical signatures, using last good copy")
+			failed = append(failed, source)
+			continue
+		}
+		fetched[source] = sigs
+	}
+
+	s.canonicalSignaturesLock.Lock()
+	for source, sigs := range fetched {
+		s.canonicalSignatureSources[source] = sigs
+	}
+
+	layered := make(map[string]*client.CanonicalSignature)
+	for _, source := range s.config.CanonicalSignatureSources {
+		for k, v := range s.canonicalSignatureSources[source] {
+			hash := strings.ToLower(k)
+			layered[hash] = &client.CanonicalSignature{
+				Hash:      hash,
+				Signature: v.Signature,
+				Source:    v.Source,
+			}
+		}
+	}
+	s.canonicalSignaturesLock.Unlock()
+
+	var upstream []*client.CanonicalSignature
+	for _, v := range layered {
+		upstream = append(upstream, v)
+	}
+
+	if err := s.db.SyncCanonicalSignatures(upstream, len(failed) == 0); err != nil {
+		return fmt.Errorf("failed to sync canonical signatures: %w", err)
+	}
+
+	if err := s.reloadCanonicalSignatures(); err != nil {
+		return err
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to refresh canonical signatures from %s", strings.Join(failed, ", "))
+	}
+
+	s.canonicalSignaturesLock.Lock()
+	s.lastCanonicalSignaturesRefresh = time.Now()
+	s.canonicalSignaturesLock.Unlock()
+
+	return nil
+}
+
+// reloadCanonicalSignatures replaces the in-memory canonical signatures with the contents of the database
+func (s *Service) reloadCanonicalSignatures() error {
+	sigs, err := s.db.LoadCanonicalSignatures()
+	if err != nil {
+		return fmt.Errorf("failed to load canonical signatures: %w", err)
+	}
+
+	newCanonicalSignatures := make(map[string]string)
+	for _, sig := range sigs {
+		newCanonicalSignatures[sig.Hash] = sig.Signature
+	}
+
+	s.canonicalSignaturesLock.Lock()
+	s.canonicalSignatures = newCanonicalSignatures
+	s.canonicalSignaturesLock.Unlock()
+
+	return nil
+}
@@ -2,57 +2,196 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
+const DefaultHost = `https://api.openchain.xyz/signature-database`
+
 type Client struct {
 	client *http.Client
 	host   string
+	apiKey string
+
+	maxRetries int
+	backoff    time.Duration
+	maxBackoff time.Duration
 }
 
-func New() *Client {
-	return &Client{
-		client: &http.Client{},
-		host:   `https://api.openchain.xyz/signature-database`,
+type Option func(c *Client)
+
+// WithHTTPClient replaces the default http client, for instance to set a timeout or a proxy
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		c.client = client
 	}
 }
 
-func (c *Client) do(method string, path string, in any, out any) error {
-	var bodyReader io.Reader
-	if in != nil {
-		b, err := json.Marshal(in)
+// WithAPIKey authenticates every request with a key token, as returned when the key was created
+func WithAPIKey(token string) Option {
+	return func(c *Client) {
+		c.apiKey = token
+	}
+}
+
+// WithRetries sets how many times a failed request is retried, and the delay before the first retry. The delay
+// doubles after each attempt, unless the server asks for a specific delay with Retry-After. Delays never exceed 30s
+func WithRetries(maxRetries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.backoff = backoff
+	}
+}
+
+// New returns a client for the public instance
+func New(options ...Option) *Client {
+	return NewWithHost(DefaultHost, options...)
+}
+
+// NewWithHost returns a client for the instance at host, such as http://localhost:34887
+func NewWithHost(host string, options ...Option) *Client {
+	c := &Client{
+		client:     &http.Client{},
+		host:       strings.TrimSuffix(host, "/"),
+		maxRetries: 3,
+		backoff:    500 * time.Millisecond,
+		maxBackoff: 30 * time.Second,
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// retryable reports whether a response means the request wasn't handled and can safely be sent again. Most errors
+// from GETs are retried, but a POST which failed part way may have been applied, so it's only retried when the server
+// says it didn't look at it
+func retryable(method string, status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return method == http.MethodGet
+	default:
+		return false
+	}
+}
+
+// send performs a request, retrying with backoff, and returns the first response which isn't worth retrying. The
+// caller must close its body
+func (c *Client) send(ctx context.Context, method string, path string, query url.Values, body []byte) (*http.Response, error) {
+	u := c.host + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	delay := c.backoff
+	for attempt := 0; ; attempt++ {
+		var bodyReader io.Reader
+		if body != nil {
+			bodyReader = bytes.NewReader(body)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, u, bodyReader)
 		if err != nil {
+			return nil, fmt.Errorf("failed to construct request: %w", err)
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if c.apiKey != "" {
+			req.Header.Set("Authorization", "Bearer "+c.apiKey)
+		}
+
+		resp, err := c.client.Do(req)
+		if err == nil && !retryable(method, resp.StatusCode) {
+			return resp, nil
+		}
+		if attempt >= c.maxRetries || ctx.Err() != nil {
+			if err != nil {
+				return nil, fmt.Errorf("failed to do request: %w", err)
+			}
+			return resp, nil
+		}
+
+		// the connection may have dropped after a post was handled, so only gets are retried on network errors
+		if err != nil && method != http.MethodGet {
+			return nil, fmt.Errorf("failed to do request: %w", err)
+		}
+
+		wait := delay
+		if resp != nil {
+			// a server can ask for any delay, but no longer than the backoff would ever wait is honoured
+			if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+				wait = time.Duration(seconds) * time.Second
+				if wait > c.maxBackoff {
+					wait = c.maxBackoff
+				}
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+
+		delay *= 2
+		if delay > c.maxBackoff {
+			delay = c.maxBackoff
+		}
+	}
+}
+
+// do performs a request against the json api, decoding the result into out if it's not nil
+func (c *Client) do(ctx context.Context, method string, path string, query url.Values, in any, out any) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
 			return fmt.Errorf("failed to marshal body: %w", err)
 		}
-		bodyReader = bytes.NewReader(b)
 	}
-	req, err := http.NewRequest(method, fmt.Sprintf("%s%s", c.host, path), bodyReader)
+
+	resp, err := c.send(ctx, method, path, query, body)
 	if err != nil {
-		return fmt.Errorf("failed to construct request: %w", err)
+		return err
 	}
+	defer resp.Body.Close()
 
-	resp, err := c.client.Do(req)
+	raw, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to do request: %w", err)
+		return fmt.Errorf("failed to read body: %w", err)
 	}
 
 	var responseWrapper struct {
-		Ok     bool            `json:"ok"`
+		Ok     *bool           `json:"ok"`
 		Error  string          `json:"error"`
 		Result json.RawMessage `json:"result"`
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&responseWrapper); err != nil {
-		return fmt.Errorf("failed to read body: %w", err)
+	if err := json.Unmarshal(raw, &responseWrapper); err != nil || responseWrapper.Ok == nil {
+		return newHTTPError(resp, raw)
 	}
 
-	if !responseWrapper.Ok {
-		return errors.New(responseWrapper.Error)
+	if !*responseWrapper.Ok {
+		return &APIError{
+			StatusCode: resp.StatusCode,
+			Message:    responseWrapper.Error,
+		}
 	}
 
+	if out == nil {
+		return nil
+	}
 	if err := json.Unmarshal(responseWrapper.Result, out); err != nil {
 		return fmt.Errorf("failed to decode body: %w", err)
 	}
@@ -60,13 +199,221 @@ func (c *Client) do(method string, path string, in any, out any) error {
 	return nil
 }
 
-func (c *Client) Import(data AllTypes[[]string]) (ImportResponse, error) {
+func (c *Client) Import(ctx context.Context, data ImportRequest) (ImportResponse, error) {
 	var resp ImportResponse
+	if err := c.do(ctx, "POST", "/v1/import", nil, data, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
 
-	err := c.do("POST", "/v1/import", ImportRequest(data), &resp)
-	if err != nil {
+func (c *Client) ImportABI(ctx context.Context, abis ...json.RawMessage) (ImportResponse, error) {
+	var resp ImportResponse
+	if err := c.do(ctx, "POST", "/v1/import/abi", nil, &ImportABIRequest{ABI: abis}, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Client) ImportSource(ctx context.Context, req *ImportSourceRequest) (ImportResponse, error) {
+	var resp ImportResponse
+	if err := c.do(ctx, "POST", "/v1/import/source", nil, req, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Client) Observe(ctx context.Context, data ImportRequest) (ImportResponse, error) {
+	var resp ImportResponse
+	if err := c.do(ctx, "POST", "/v1/observe", nil, data, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type LookupOptions struct {
+	// Unfiltered includes function signatures which aren't the canonical one for their hash
+	Unfiltered bool
+	// Metadata includes when and how each signature was submitted
+	Metadata bool
+}
+
+// Lookup returns the signatures known for each hash
+func (c *Client) Lookup(ctx context.Context, hashes AllTypes[[]string], opts *LookupOptions) (SignatureResponse, error) {
+	query := make(url.Values)
+	for typ, values := range hashes {
+		if len(values) > 0 {
+			query.Set(string(typ), strings.Join(values, ","))
+		}
+	}
+	if opts != nil && opts.Unfiltered {
+		query.Set("filter", "false")
+	}
+	if opts != nil && opts.Metadata {
+		query.Set("metadata", "true")
+	}
+
+	var resp SignatureResponse
+	if err := c.do(ctx, "GET", "/v1/lookup", query, nil, &resp); err != nil {
 		return nil, err
 	}
+	return resp, nil
+}
+
+type SearchQuery struct {
+	// Query matches names with * and ? wildcards
+	Query string
+	Name  string
+	Args  string
+	Param string
+	Type  SignatureType
+
+	// Cursor is the NextCursor of the previous page
+	Cursor     string
+	Limit      int
+	Unfiltered bool
+}
+
+func (c *Client) Search(ctx context.Context, q *SearchQuery) (*SearchResponse, error) {
+	query := make(url.Values)
+	for key, value := range map[string]string{
+		"query":  q.Query,
+		"name":   q.Name,
+		"args":   q.Args,
+		"param":  q.Param,
+		"type":   string(q.Type),
+		"cursor": q.Cursor,
+	} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	if q.Limit > 0 {
+		query.Set("limit", strconv.Itoa(q.Limit))
+	}
+	if q.Unfiltered {
+		query.Set("filter", "false")
+	}
 
+	var resp SearchResponse
+	if err := c.do(ctx, "GET", "/v2/search", query, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *Client) Stats(ctx context.Context) (*StatsResponse, error) {
+	var resp StatsResponse
+	if err := c.do(ctx, "GET", "/v1/stats", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Decode trial-decodes calldata or a log against every known signature for its selector or topic
+func (c *Client) Decode(ctx context.Context, req *DecodeRequest, unfiltered bool) (*DecodeResponse, error) {
+	query := make(url.Values)
+	if unfiltered {
+		query.Set("filter", "false")
+	}
+
+	var resp DecodeResponse
+	if err := c.do(ctx, "POST", "/v1/decode", query, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+type CollisionsQuery struct {
+	Type           SignatureType
+	Classification CollisionClassification
+	Hash           string
+	// Since only returns collisions which changed after it
+	Since  time.Time
+	Limit  int
+	Offset int
+}
+
+func (c *Client) Collisions(ctx context.Context, q *CollisionsQuery) (*CollisionsResponse, error) {
+	query := make(url.Values)
+	if q.Type != "" {
+		query.Set("type", string(q.Type))
+	}
+	if q.Classification != "" {
+		query.Set("classification", string(q.Classification))
+	}
+	if q.Hash != "" {
+		query.Set("hash", q.Hash)
+	}
+	if !q.Since.IsZero() {
+		query.Set("since", q.Since.Format(time.RFC3339))
+	}
+	if q.Limit > 0 {
+		query.Set("limit", strconv.Itoa(q.Limit))
+	}
+	if q.Offset > 0 {
+		query.Set("offset", strconv.Itoa(q.Offset))
+	}
+
+	var resp CollisionsResponse
+	if err := c.do(ctx, "GET", "/v1/collisions", query, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// RefreshCanonical reloads the canonical signatures from their upstream sources. It requires an admin key
+func (c *Client) RefreshCanonical(ctx context.Context) error {
+	return c.do(ctx, "POST", "/v1/refresh_canonical_signatures", nil, nil, nil)
+}
+
+func (c *Client) ListCanonical(ctx context.Context) ([]*CanonicalSignature, error) {
+	var resp []*CanonicalSignature
+	if err := c.do(ctx, "GET", "/v1/canonical", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// AddCanonical adds or replaces a manual canonical signature. The hash may be left empty
+func (c *Client) AddCanonical(ctx context.Context, sig *CanonicalSignature) error {
+	return c.do(ctx, "POST", "/v1/canonical", nil, sig, nil)
+}
+
+func (c *Client) DeleteCanonical(ctx context.Context, hash string) error {
+	return c.do(ctx, "DELETE", "/v1/canonical/"+url.PathEscape(hash), nil, nil, nil)
+}
+
+// Audit runs a consistency audit, using the configured mode if mode is empty
+func (c *Client) Audit(ctx context.Context, mode AuditMode) (*AuditReport, error) {
+	query := make(url.Values)
+	if mode != "" {
+		query.Set("mode", string(mode))
+	}
+
+	var resp AuditReport
+	if err := c.do(ctx, "POST", "/v1/audit", query, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *Client) ListKeys(ctx context.Context) ([]*Key, error) {
+	var resp []*Key
+	if err := c.do(ctx, "GET", "/v1/keys", nil, nil, &resp); err != nil {
+		return nil, err
+	}
 	return resp, nil
 }
+
+func (c *Client) CreateKey(ctx context.Context, req *CreateKeyRequest) (*CreateKeyResponse, error) {
+	var resp CreateKeyResponse
+	if err := c.do(ctx, "POST", "/v1/keys", nil, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *Client) RevokeKey(ctx context.Context, id string) error {
+	return c.do(ctx, "DELETE", "/v1/keys/"+url.PathEscape(id), nil, nil, nil)
+}
@@ -0,0 +1,229 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func respond(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func newTestClient(t *testing.T, handler http.HandlerFunc, options ...Option) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return NewWithHost(server.URL+"/", append([]Option{WithRetries(2, time.Millisecond)}, options...)...)
+}
+
+func Test_Lookup(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/lookup", r.URL.Path)
+		assert.Equal(t, "0xa9059cbb,0x095ea7b3", r.URL.Query().Get("function"))
+		assert.False(t, r.URL.Query().Has("event"))
+		assert.Equal(t, "false", r.URL.Query().Get("filter"))
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+		respond(w, http.StatusOK, map[string]any{
+			"ok": true,
+			"result": map[string]any{
+				"function": map[string]any{
+					"0xa9059cbb": []any{map[string]any{"name": "transfer(address,uint256)", "filtered": false}},
+					"0x095ea7b3": []any{},
+				},
+			},
+		})
+	}, WithAPIKey("secret"))
+
+	resp, err := c.Lookup(context.Background(), AllTypes[[]string]{
+		SignatureTypeFunction: {"0xa9059cbb", "0x095ea7b3"},
+	}, &LookupOptions{Unfiltered: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "transfer(address,uint256)", resp[SignatureTypeFunction]["0xa9059cbb"][0].Name)
+	assert.Empty(t, resp[SignatureTypeFunction]["0x095ea7b3"])
+}
+
+func Test_Errors(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		respond(w, http.StatusBadRequest, map[string]any{"ok": false, "error": "limit must be between 1 and 1000"})
+	})
+
+	_, err := c.Search(context.Background(), &SearchQuery{Query: "transfer*", Limit: 5000})
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	assert.Equal(t, "limit must be between 1 and 1000", apiErr.Message)
+	assert.False(t, apiErr.Temporary())
+
+	c = newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, "<html>blocked</html>")
+	})
+
+	_, err = c.Stats(context.Background())
+	var httpErr *HTTPError
+	assert.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, http.StatusForbidden, httpErr.StatusCode)
+	assert.Equal(t, "<html>blocked</html>", httpErr.Body)
+	assert.False(t, errors.As(err, &apiErr))
+}
+
+func Test_Retry(t *testing.T) {
+	var attempts atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			respond(w, http.StatusTooManyRequests, map[string]any{"ok": false, "error": "rate limited"})
+			return
+		}
+		respond(w, http.StatusOK, map[string]any{"ok": true, "result": map[string]any{"count": map[string]int{"function": 3}}})
+	})
+
+	stats, err := c.Stats(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, stats.Count[SignatureTypeFunction])
+	assert.EqualValues(t, 3, attempts.Load())
+
+	// the last failure is returned once retries run out
+	attempts.Store(-10)
+	_, err = c.Stats(context.Background())
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.True(t, apiErr.Temporary())
+	assert.EqualValues(t, -7, attempts.Load())
+}
+
+func Test_RetryOnlyIdempotent(t *testing.T) {
+	var attempts atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		respond(w, http.StatusInternalServerError, map[string]any{"ok": false, "error": "failed to save signatures to db"})
+	})
+
+	_, err := c.Import(context.Background(), ImportRequest{SignatureTypeFunction: {"transfer(address,uint256)"}})
+	assert.Error(t, err)
+	assert.EqualValues(t, 1, attempts.Load())
+
+	_, err = c.Stats(context.Background())
+	assert.Error(t, err)
+	assert.EqualValues(t, 4, attempts.Load())
+}
+
+func Test_RetryCancelled(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		respond(w, http.StatusServiceUnavailable, map[string]any{"ok": false, "error": "export is not ready yet"})
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.Stats(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 10*time.Second)
+}
+
+func Test_Export(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/export", r.URL.Path)
+		assert.Equal(t, "ndjson", r.URL.Query().Get("format"))
+		assert.Equal(t, "event", r.URL.Query().Get("type"))
+
+		if r.URL.Query().Get("since_id") == "-1" {
+			respond(w, http.StatusBadRequest, map[string]any{"ok": false, "error": "since_id must be a non-negative integer"})
+			return
+		}
+		assert.Equal(t, "0", r.URL.Query().Get("since_id"))
+
+		w.Header().Set("X-Export-Version", "42")
+		io.WriteString(w, `{"id":7,"type":"event","hash":"0xddf2","name":"Transfer(address,address,uint256)"}`+"\n")
+		io.WriteString(w, `{"id":9,"type":"event","hash":"0x8c5b","name":"Approval(address,address,uint256)"}`+"\n")
+		io.WriteString(w, `{"end":true,"count":2}`+"\n")
+	})
+
+	since := int64(0)
+	export, err := c.Export(context.Background(), &ExportOptions{Type: SignatureTypeEvent, SinceID: &since})
+	assert.NoError(t, err)
+	defer export.Close()
+	assert.EqualValues(t, 42, export.Version)
+
+	var names []string
+	for {
+		sig, err := export.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		names = append(names, sig.Name)
+	}
+	assert.Equal(t, []string{"Transfer(address,address,uint256)", "Approval(address,address,uint256)"}, names)
+	_, err = export.Next()
+	assert.Equal(t, io.EOF, err)
+
+	since = -1
+	_, err = c.Export(context.Background(), &ExportOptions{Type: SignatureTypeEvent, SinceID: &since})
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "since_id must be a non-negative integer", apiErr.Message)
+}
+
+func Test_ExportTruncated(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Export-Version", "42")
+		io.WriteString(w, `{"id":7,"type":"event","hash":"0xddf2","name":"Transfer(address,address,uint256)"}`+"\n")
+		if r.URL.Query().Get("type") == "function" {
+			io.WriteString(w, `{"end":true,"count":2}`+"\n")
+		}
+	})
+
+	// a stream which stops without its trailer was cut short, rather than ending
+	export, err := c.Export(context.Background(), &ExportOptions{Type: SignatureTypeEvent})
+	assert.NoError(t, err)
+	defer export.Close()
+	sig, err := export.Next()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 7, sig.ID)
+	_, err = export.Next()
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	// as is one whose trailer doesn't count the rows which were sent
+	export, err = c.Export(context.Background(), &ExportOptions{Type: SignatureTypeFunction})
+	assert.NoError(t, err)
+	defer export.Close()
+	_, err = export.Next()
+	assert.NoError(t, err)
+	_, err = export.Next()
+	assert.Error(t, err)
+	assert.NotEqual(t, io.EOF, err)
+}
+
+func Test_RetryAfterCapped(t *testing.T) {
+	attempts := 0
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.Header().Set("Retry-After", "3600")
+			respond(w, http.StatusTooManyRequests, map[string]any{"ok": false, "error": "rate limit exceeded"})
+			return
+		}
+		respond(w, http.StatusOK, map[string]any{"ok": true, "result": map[string]any{}})
+	})
+	c.maxBackoff = 10 * time.Millisecond
+
+	start := time.Now()
+	_, err := c.Stats(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+	assert.Less(t, time.Since(start), time.Second)
+}
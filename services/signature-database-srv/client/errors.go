@@ -0,0 +1,48 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// HTTPError is returned when the server responds with something other than the api's json envelope, such as an error
+// page from a proxy in front of it
+type HTTPError struct {
+	StatusCode int
+	Status     string
+	// Body is the start of the response, for diagnosis
+	Body string
+}
+
+// how much of an unexpected response to keep
+const maxHTTPErrorBody = 512
+
+func newHTTPError(resp *http.Response, body []byte) *HTTPError {
+	if len(body) > maxHTTPErrorBody {
+		body = body[:maxHTTPErrorBody]
+	}
+	return &HTTPError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Body:       string(body),
+	}
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("unexpected response: %s", e.Status)
+}
+
+// APIError is returned when the api handled the request but rejected it, responding with ok:false
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+// Temporary reports whether the request may succeed if tried again later
+func (e *APIError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
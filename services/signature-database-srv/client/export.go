@@ -0,0 +1,174 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type ExportOptions struct {
+	// Type limits the export to one type of signature
+	Type SignatureType
+	// SinceID makes the export incremental, returning only signatures with a greater id in id order. Pass the Version
+	// of the previous export to pick up where it left off. Zero starts from the beginning, but still in id order
+	SinceID *int64
+	// Since makes the export incremental, returning only signatures added after it
+	Since time.Time
+}
+
+// ExportStream reads an export one signature at a time. It must be closed
+type ExportStream struct {
+	*ExportReader
+
+	// Version is the highest signature id the export covers
+	Version int64
+
+	body io.ReadCloser
+}
+
+// Export streams the database, or part of it. Full exports come from a snapshot which is regenerated periodically
+func (c *Client) Export(ctx context.Context, opts *ExportOptions) (*ExportStream, error) {
+	query := url.Values{
+		"format": {string(ExportFormatNDJSON)},
+	}
+	if opts != nil {
+		if opts.Type != "" {
+			query.Set("type", string(opts.Type))
+		}
+		if opts.SinceID != nil {
+			query.Set("since_id", strconv.FormatInt(*opts.SinceID, 10))
+		}
+		if !opts.Since.IsZero() {
+			query.Set("since", opts.Since.Format(time.RFC3339))
+		}
+	}
+
+	resp, err := c.send(ctx, "GET", "/v1/export", query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+
+		// failures come back in the usual envelope
+		raw, _ := io.ReadAll(resp.Body)
+		var responseWrapper struct {
+			Ok    *bool  `json:"ok"`
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(raw, &responseWrapper); err == nil && responseWrapper.Ok != nil {
+			return nil, &APIError{StatusCode: resp.StatusCode, Message: responseWrapper.Error}
+		}
+		return nil, newHTTPError(resp, raw)
+	}
+
+	version, err := strconv.ParseInt(resp.Header.Get("X-Export-Version"), 10, 64)
+	if err != nil {
+		resp.Body.Close()
+		return nil, fmt.Errorf("server did not report an export version")
+	}
+
+	return &ExportStream{
+		ExportReader: NewExportReader(resp.Body),
+		Version:      version,
+		body:         resp.Body,
+	}, nil
+}
+
+func (e *ExportStream) Close() error {
+	return e.body.Close()
+}
+
+// ExportTrailerPrefix starts the trailer row of a csv export, which is followed by the count and empty columns
+const ExportTrailerPrefix = "#end"
+
+// TruncatedExportError is returned for an export which ended without its trailer, so was cut short. It wraps
+// io.ErrUnexpectedEOF
+type TruncatedExportError struct {
+	// Count is the number of signatures read before the export ended
+	Count int64
+}
+
+func (e *TruncatedExportError) Error() string {
+	return fmt.Sprintf("export ended without its trailer after %d signatures: %v", e.Count, io.ErrUnexpectedEOF)
+}
+
+func (e *TruncatedExportError) Unwrap() error {
+	return io.ErrUnexpectedEOF
+}
+
+// Check returns an error unless the trailer ends an export of count signatures
+func (t *ExportTrailer) Check(count int64) error {
+	if !t.End || t.Count != count {
+		return fmt.Errorf("export trailer counts %d signatures but %d were read", t.Count, count)
+	}
+	return nil
+}
+
+// ParseExportTrailer returns the trailer if the line of an ndjson export is one, or nil if it's a signature
+func ParseExportTrailer(line []byte) (*ExportTrailer, error) {
+	if !bytes.HasPrefix(line, []byte(`{"end"`)) {
+		return nil, nil
+	}
+
+	var trailer ExportTrailer
+	if err := json.Unmarshal(line, &trailer); err != nil {
+		return nil, fmt.Errorf("failed to decode trailer: %w", err)
+	}
+	return &trailer, nil
+}
+
+// ExportReader reads an ndjson export one signature at a time, such as one saved to a file
+type ExportReader struct {
+	scanner  *bufio.Scanner
+	count    int64
+	complete bool
+}
+
+func NewExportReader(r io.Reader) *ExportReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	return &ExportReader{scanner: scanner}
+}
+
+// Next returns the next signature, or io.EOF at the end of the export. The server ends every ndjson export with a
+// trailer, so one which stops without it was cut short and returns a TruncatedExportError, though the signatures
+// returned before that are still whole
+func (e *ExportReader) Next() (*ExportedSignature, error) {
+	if e.complete {
+		return nil, io.EOF
+	}
+	if !e.scanner.Scan() {
+		if err := e.scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read export: %w", err)
+		}
+		return nil, &TruncatedExportError{Count: e.count}
+	}
+
+	line := e.scanner.Bytes()
+	trailer, err := ParseExportTrailer(line)
+	if err != nil {
+		return nil, err
+	} else if trailer != nil {
+		if err := trailer.Check(e.count); err != nil {
+			return nil, err
+		}
+		e.complete = true
+		return nil, io.EOF
+	}
+
+	var sig ExportedSignature
+	if err := json.Unmarshal(line, &sig); err != nil {
+		return nil, fmt.Errorf("failed to decode signature: %w", err)
+	}
+	e.count++
+	return &sig, nil
+}
@@ -0,0 +1,86 @@
+package resolver
+
+import (
+	"compress/gzip"
+	"fmt"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/client"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fallbackRetryInterval is how long a fallback export which failed to load is left before it's read again, so that a
+// file which is missing when the service first goes down is picked up once it's there, without every lookup rereading
+// a broken one
+const fallbackRetryInterval = time.Minute
+
+// fallback answers lookups from an ndjson export on disk, such as one saved from /v1/export?format=ndjson. The file
+// is only read the first time it's needed, since it may be large and the service is usually reachable
+type fallback struct {
+	path          string
+	retryInterval time.Duration
+
+	lock       sync.Mutex
+	signatures map[string][]*client.SignatureData
+	err        error
+	failedAt   time.Time
+}
+
+func (f *fallback) lookup(key string) ([]*client.SignatureData, error) {
+	f.lock.Lock()
+	if f.signatures == nil && time.Since(f.failedAt) >= f.retryInterval {
+		if f.signatures, f.err = loadExport(f.path); f.err != nil {
+			f.failedAt = time.Now()
+		}
+	}
+	signatures, err := f.signatures, f.err
+	f.lock.Unlock()
+
+	if signatures == nil {
+		return nil, err
+	}
+
+	sigs, ok := signatures[key]
+	if !ok {
+		return []*client.SignatureData{}, nil
+	}
+	return sigs, nil
+}
+
+func loadExport(path string) (map[string][]*client.SignatureData, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open fallback export: %w", err)
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress fallback export: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	signatures := make(map[string][]*client.SignatureData)
+
+	// a file cut short while it was being saved must not be mistaken for every signature there is
+	reader := client.NewExportReader(r)
+	for {
+		sig, err := reader.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read fallback export: %w", err)
+		}
+
+		key := cacheKey(sig.Type, sig.Hash)
+		signatures[key] = append(signatures[key], &client.SignatureData{Name: sig.Name})
+	}
+
+	return signatures, nil
+}
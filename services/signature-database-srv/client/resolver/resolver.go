@@ -0,0 +1,247 @@
+// Package resolver resolves hashes to signatures for callers which look up a lot of them, such as indexers. Lookups
+// made around the same time are coalesced into batched requests, and results are cached, including for hashes which
+// have no known signatures.
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/client"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxQuerySize bounds the hashes sent in one request, since many servers and proxies refuse request lines longer
+// than 8KiB. That's what limits batches of event hashes, which are 66 characters each
+const maxQuerySize = 6144
+
+type Option func(r *Resolver)
+
+// WithCacheSize sets how many hashes are cached. Zero disables caching
+func WithCacheSize(size int) Option {
+	return func(r *Resolver) {
+		r.cache = newCache(size)
+	}
+}
+
+// WithTTL sets how long results are cached for. Hashes without any known signatures are cached for negativeTTL, which
+// is usually shorter since someone may import one at any time
+func WithTTL(ttl time.Duration, negativeTTL time.Duration) Option {
+	return func(r *Resolver) {
+		r.ttl = ttl
+		r.negativeTTL = negativeTTL
+	}
+}
+
+// WithBatching sets how long a lookup may wait for others to share a request with, and the most hashes in a request.
+// The service rejects requests with more than 250 hashes by default. Batches are also sent early once their hashes
+// would make the request too long to send as a query
+func WithBatching(window time.Duration, maxBatch int) Option {
+	return func(r *Resolver) {
+		r.window = window
+		r.maxBatch = maxBatch
+	}
+}
+
+// WithFallback answers lookups from an ndjson export, optionally gzipped, when the service can't be reached. Fallback
+// results aren't filtered against the canonical signatures, and aren't cached. An export missing its trailer is rejected
+func WithFallback(path string) Option {
+	return func(r *Resolver) {
+		r.fallback = &fallback{path: path, retryInterval: fallbackRetryInterval}
+	}
+}
+
+// WithUnfiltered includes function signatures which aren't the canonical one for their hash
+func WithUnfiltered() Option {
+	return func(r *Resolver) {
+		r.unfiltered = true
+	}
+}
+
+// WithRequestTimeout bounds each batched request. Callers' contexts only bound how long they wait, since a request
+// is shared by everyone in the batch
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(r *Resolver) {
+		r.requestTimeout = timeout
+	}
+}
+
+// call is a hash which has been queued or sent, and which anyone else looking it up can wait on
+type call struct {
+	typ  client.SignatureType
+	hash string
+	key  string
+
+	done   chan struct{}
+	result []*client.SignatureData
+	err    error
+}
+
+type Resolver struct {
+	client   *client.Client
+	cache    *cache
+	fallback *fallback
+
+	ttl            time.Duration
+	negativeTTL    time.Duration
+	window         time.Duration
+	maxBatch       int
+	unfiltered     bool
+	requestTimeout time.Duration
+
+	lock        sync.Mutex
+	pending     []*call
+	pendingSize int
+	inflight    map[string]*call
+	timer       *time.Timer
+}
+
+func New(c *client.Client, options ...Option) *Resolver {
+	r := &Resolver{
+		client:         c,
+		cache:          newCache(100_000),
+		ttl:            time.Hour,
+		negativeTTL:    5 * time.Minute,
+		window:         10 * time.Millisecond,
+		maxBatch:       250,
+		requestTimeout: 30 * time.Second,
+		inflight:       make(map[string]*call),
+	}
+	for _, option := range options {
+		option(r)
+	}
+	return r
+}
+
+func cacheKey(typ client.SignatureType, hash string) string {
+	return string(typ) + ":" + strings.ToLower(hash)
+}
+
+// Lookup returns the signatures for a hash, which are empty if there are none
+func (r *Resolver) Lookup(ctx context.Context, typ client.SignatureType, hash string) ([]*client.SignatureData, error) {
+	result, err := r.LookupMany(ctx, typ, []string{hash})
+	if err != nil {
+		return nil, err
+	}
+	return result[hash], nil
+}
+
+// LookupMany returns the signatures for several hashes of the same type, keyed by the hashes as they were given
+func (r *Resolver) LookupMany(ctx context.Context, typ client.SignatureType, hashes []string) (map[string][]*client.SignatureData, error) {
+	result := make(map[string][]*client.SignatureData)
+	waiting := make(map[string]*call)
+
+	for _, hash := range hashes {
+		key := cacheKey(typ, hash)
+		if sigs, ok := r.cache.get(key); ok {
+			result[hash] = sigs
+			continue
+		}
+		waiting[hash] = r.enqueue(typ, hash, key)
+	}
+
+	for hash, c := range waiting {
+		select {
+		case <-c.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if c.err != nil {
+			return nil, c.err
+		}
+		result[hash] = c.result
+	}
+
+	return result, nil
+}
+
+// enqueue returns the call for a hash, joining one which is already queued or in flight if there is one
+func (r *Resolver) enqueue(typ client.SignatureType, hash string, key string) *call {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if c, ok := r.inflight[key]; ok {
+		return c
+	}
+
+	c := &call{
+		typ:  typ,
+		hash: strings.ToLower(hash),
+		key:  key,
+		done: make(chan struct{}),
+	}
+	r.inflight[key] = c
+	r.pending = append(r.pending, c)
+	r.pendingSize += len(c.hash) + 1
+
+	if len(r.pending) >= r.maxBatch || r.pendingSize >= maxQuerySize {
+		batch := r.pending
+		r.pending = nil
+		r.pendingSize = 0
+		if r.timer != nil {
+			r.timer.Stop()
+		}
+		go r.send(batch)
+	} else if len(r.pending) == 1 {
+		r.timer = time.AfterFunc(r.window, r.flush)
+	}
+
+	return c
+}
+
+func (r *Resolver) flush() {
+	r.lock.Lock()
+	batch := r.pending
+	r.pending = nil
+	r.pendingSize = 0
+	r.lock.Unlock()
+
+	if len(batch) > 0 {
+		r.send(batch)
+	}
+}
+
+func (r *Resolver) send(batch []*call) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.requestTimeout)
+	defer cancel()
+
+	hashes := make(client.AllTypes[[]string])
+	for _, c := range batch {
+		hashes[c.typ] = append(hashes[c.typ], c.hash)
+	}
+
+	resp, err := r.client.Lookup(ctx, hashes, &client.LookupOptions{Unfiltered: r.unfiltered})
+	for _, c := range batch {
+		switch {
+		case err == nil:
+			c.result = resp[c.typ][c.hash]
+			if c.result == nil {
+				c.result = []*client.SignatureData{}
+			}
+
+			ttl := r.ttl
+			if len(c.result) == 0 {
+				ttl = r.negativeTTL
+			}
+			r.cache.add(c.key, c.result, ttl)
+		case r.fallback != nil:
+			var fallbackErr error
+			if c.result, fallbackErr = r.fallback.lookup(c.key); fallbackErr != nil {
+				c.err = fmt.Errorf("failed to look up signatures: %w, and the fallback failed: %s", err, fallbackErr)
+			}
+		default:
+			c.err = fmt.Errorf("failed to look up signatures: %w", err)
+		}
+	}
+
+	r.lock.Lock()
+	for _, c := range batch {
+		delete(r.inflight, c.key)
+	}
+	r.lock.Unlock()
+
+	for _, c := range batch {
+		close(c.done)
+	}
+}
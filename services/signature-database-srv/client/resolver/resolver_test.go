@@ -0,0 +1,221 @@
+package resolver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/client"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var known = map[string]string{
+	"0xa9059cbb": "transfer(address,uint256)",
+	"0x095ea7b3": "approve(address,uint256)",
+}
+
+type testServer struct {
+	requests atomic.Int32
+	hashes   atomic.Int32
+	down     atomic.Bool
+	maxQuery atomic.Int32
+}
+
+func newTestServer(t *testing.T) (*testServer, *client.Client) {
+	ts := &testServer{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ts.requests.Add(1)
+		if size := int32(len(r.URL.RawQuery)); size > ts.maxQuery.Load() {
+			ts.maxQuery.Store(size)
+		}
+		if ts.down.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		result := make(map[string][]*client.SignatureData)
+		for _, hash := range strings.Split(r.URL.Query().Get("function"), ",") {
+			ts.hashes.Add(1)
+			result[hash] = []*client.SignatureData{}
+			if name, ok := known[hash]; ok {
+				result[hash] = append(result[hash], &client.SignatureData{Name: name})
+			}
+		}
+
+		json.NewEncoder(w).Encode(map[string]any{
+			"ok":     true,
+			"result": map[string]any{"function": result},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	return ts, client.NewWithHost(server.URL, client.WithRetries(0, 0))
+}
+
+func Test_Batching(t *testing.T) {
+	ts, c := newTestServer(t)
+	r := New(c, WithBatching(50*time.Millisecond, 250))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			hash := "0xa9059cbb"
+			if i%2 == 1 {
+				hash = "0x095EA7B3"
+			}
+
+			sigs, err := r.Lookup(context.Background(), client.SignatureTypeFunction, hash)
+			assert.NoError(t, err)
+			assert.Len(t, sigs, 1)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.EqualValues(t, 1, ts.requests.Load())
+	assert.EqualValues(t, 2, ts.hashes.Load())
+
+	// now cached
+	sigs, err := r.Lookup(context.Background(), client.SignatureTypeFunction, "0xa9059cbb")
+	assert.NoError(t, err)
+	assert.Equal(t, "transfer(address,uint256)", sigs[0].Name)
+	assert.EqualValues(t, 1, ts.requests.Load())
+}
+
+func Test_MaxBatch(t *testing.T) {
+	ts, c := newTestServer(t)
+	r := New(c, WithBatching(time.Hour, 2))
+
+	result, err := r.LookupMany(context.Background(), client.SignatureTypeFunction, []string{"0x00000001", "0x00000002", "0x00000003", "0x00000004"})
+	assert.NoError(t, err)
+	assert.Len(t, result, 4)
+	assert.EqualValues(t, 2, ts.requests.Load())
+}
+
+func Test_MaxQuerySize(t *testing.T) {
+	ts, c := newTestServer(t)
+	r := New(c, WithBatching(10*time.Millisecond, 1000))
+
+	var hashes []string
+	for i := 0; i < 600; i++ {
+		hashes = append(hashes, fmt.Sprintf("0x%08x", i))
+	}
+	result, err := r.LookupMany(context.Background(), client.SignatureTypeFunction, hashes)
+	assert.NoError(t, err)
+	assert.Len(t, result, 600)
+	assert.EqualValues(t, 2, ts.requests.Load())
+	assert.Less(t, ts.maxQuery.Load(), int32(8192))
+}
+
+func Test_NegativeCache(t *testing.T) {
+	ts, c := newTestServer(t)
+	r := New(c, WithBatching(time.Millisecond, 250), WithTTL(time.Hour, 50*time.Millisecond))
+
+	for i := 0; i < 3; i++ {
+		sigs, err := r.Lookup(context.Background(), client.SignatureTypeFunction, "0xdeadbeef")
+		assert.NoError(t, err)
+		assert.Empty(t, sigs)
+	}
+	assert.EqualValues(t, 1, ts.requests.Load())
+
+	time.Sleep(100 * time.Millisecond)
+	_, err := r.Lookup(context.Background(), client.SignatureTypeFunction, "0xdeadbeef")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, ts.requests.Load())
+}
+
+func Test_Cache(t *testing.T) {
+	c := newCache(2)
+	now := time.Unix(0, 0)
+	c.now = func() time.Time { return now }
+
+	c.add("a", []*client.SignatureData{{Name: "a()"}}, time.Minute)
+	c.add("b", []*client.SignatureData{{Name: "b()"}}, time.Minute)
+	_, ok := c.get("a")
+	assert.True(t, ok)
+
+	// b is now the least recently used
+	c.add("c", []*client.SignatureData{{Name: "c()"}}, time.Minute)
+	_, ok = c.get("b")
+	assert.False(t, ok)
+	_, ok = c.get("a")
+	assert.True(t, ok)
+
+	now = now.Add(2 * time.Minute)
+	_, ok = c.get("a")
+	assert.False(t, ok)
+}
+
+func Test_Fallback(t *testing.T) {
+	ts, c := newTestServer(t)
+	ts.down.Store(true)
+
+	_, err := New(c, WithBatching(time.Millisecond, 250)).Lookup(context.Background(), client.SignatureTypeFunction, "0xa9059cbb")
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "export.ndjson")
+	assert.NoError(t, os.WriteFile(path, []byte(
+		`{"id":1,"type":"function","hash":"0xa9059cbb","name":"transfer(address,uint256)"}`+"\n"+
+			`{"id":2,"type":"event","hash":"0x00000000","name":"Unrelated()"}`+"\n"+
+			`{"end":true,"count":2}`+"\n",
+	), 0644))
+
+	r := New(c, WithBatching(time.Millisecond, 250), WithFallback(path))
+	result, err := r.LookupMany(context.Background(), client.SignatureTypeFunction, []string{"0xa9059cbb", "0x00000000"})
+	assert.NoError(t, err)
+	assert.Equal(t, "transfer(address,uint256)", result["0xa9059cbb"][0].Name)
+	assert.Empty(t, result["0x00000000"])
+
+	// fallback results aren't cached, so the service is asked again once it's back
+	ts.down.Store(false)
+	requests := ts.requests.Load()
+	_, err = r.Lookup(context.Background(), client.SignatureTypeFunction, "0xa9059cbb")
+	assert.NoError(t, err)
+	assert.Equal(t, requests+1, ts.requests.Load())
+}
+
+func Test_FallbackRetry(t *testing.T) {
+	ts, c := newTestServer(t)
+	ts.down.Store(true)
+
+	path := filepath.Join(t.TempDir(), "export.ndjson")
+	r := New(c, WithBatching(time.Millisecond, 250), WithFallback(path))
+	_, err := r.Lookup(context.Background(), client.SignatureTypeFunction, "0xa9059cbb")
+	assert.Error(t, err)
+
+	// the export isn't read again until the retry interval has passed
+	line := `{"id":1,"type":"function","hash":"0xa9059cbb","name":"transfer(address,uint256)"}` + "\n"
+	assert.NoError(t, os.WriteFile(path, []byte(line), 0644))
+	_, err = r.Lookup(context.Background(), client.SignatureTypeFunction, "0xa9059cbb")
+	assert.Error(t, err)
+
+	// an export without its trailer was cut short, so it isn't used
+	r.fallback.retryInterval = 0
+	_, err = r.Lookup(context.Background(), client.SignatureTypeFunction, "0xa9059cbb")
+	assert.ErrorContains(t, err, "without its trailer")
+
+	assert.NoError(t, os.WriteFile(path, []byte(line+`{"end":true,"count":1}`+"\n"), 0644))
+	sigs, err := r.Lookup(context.Background(), client.SignatureTypeFunction, "0xa9059cbb")
+	assert.NoError(t, err)
+	assert.Equal(t, "transfer(address,uint256)", sigs[0].Name)
+}
+
+func Test_CallerCancelled(t *testing.T) {
+	_, c := newTestServer(t)
+	r := New(c, WithBatching(time.Hour, 250))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := r.Lookup(ctx, client.SignatureTypeFunction, "0xa9059cbb")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -1,30 +1,66 @@
 package client
 
+import (
+	"encoding/json"
+	"time"
+)
+
 type SignatureType string
 
 const (
 	SignatureTypeFunction SignatureType = "function"
 	SignatureTypeEvent                  = "event"
+	SignatureTypeError                  = "error"
 )
 
 func SignatureTypes() []SignatureType {
-	return []SignatureType{SignatureTypeFunction, SignatureTypeEvent}
+	return []SignatureType{SignatureTypeFunction, SignatureTypeEvent, SignatureTypeError}
 }
 
 func (t SignatureType) Valid() bool {
-	return t == SignatureTypeFunction || t == SignatureTypeEvent
+	return t == SignatureTypeFunction || t == SignatureTypeEvent || t == SignatureTypeError
+}
+
+// HashLength is the number of bytes of the keccak256 of a signature which make up its hash
+func (t SignatureType) HashLength() int {
+	if t == SignatureTypeEvent {
+		return 32
+	}
+	return 4
 }
 
 type AllTypes[T any] map[SignatureType]T
 
 type ImportRequest AllTypes[[]string]
 
+type ImportABIRequest struct {
+	ABI []json.RawMessage `json:"abi"`
+}
+
+type SourceLanguage string
+
+const (
+	SourceLanguageSolidity SourceLanguage = "solidity"
+	SourceLanguageVyper    SourceLanguage = "vyper"
+)
+
+type ImportSourceRequest struct {
+	Language SourceLanguage `json:"language"`
+	Version  string         `json:"version"`
+
+	// Source is a single source file. For Solidity, Input may be set instead to a standard-JSON input.
+	Source string          `json:"source,omitempty"`
+	Input  json.RawMessage `json:"input,omitempty"`
+}
+
 type ImportResponse AllTypes[*ImportResponseDetails]
 
 type ImportResponseDetails struct {
 	Imported   map[string]string `json:"imported"`
 	Duplicated map[string]string `json:"duplicated"`
 	Invalid    []string          `json:"invalid"`
+	// Rewritten maps submitted declarations to the canonical signatures they were normalized to
+	Rewritten map[string]string `json:"rewritten"`
 }
 
 func NewImportResponse() ImportResponse {
@@ -40,12 +76,40 @@ func NewImportResponseDetails() *ImportResponseDetails {
 		Imported:   make(map[string]string),
 		Duplicated: make(map[string]string),
 		Invalid:    nil,
+		Rewritten:  make(map[string]string),
 	}
 }
 
+// SignatureSource describes how a signature first made it into the database
+type SignatureSource string
+
+const (
+	SignatureSourceImport    SignatureSource = "import"
+	SignatureSourceABI       SignatureSource = "abi"
+	SignatureSourceCompiled  SignatureSource = "source"
+	SignatureSourceCanonical SignatureSource = "canonical"
+	SignatureSourceOnChain   SignatureSource = "onchain"
+	SignatureSourceSync      SignatureSource = "sync"
+)
+
+func (s SignatureSource) Valid() bool {
+	switch s {
+	case SignatureSourceImport, SignatureSourceABI, SignatureSourceCompiled, SignatureSourceCanonical, SignatureSourceOnChain, SignatureSourceSync:
+		return true
+	}
+	return false
+}
+
 type SignatureData struct {
 	Name     string `json:"name"`
 	Filtered bool   `json:"filtered"`
+	// Score counts how many times the signature was observed on-chain or in verified source
+	Score int64 `json:"score"`
+
+	// metadata is only populated when requested, and may be missing for signatures imported before it was tracked
+	CreatedAt *time.Time      `json:"created_at,omitempty"`
+	Source    SignatureSource `json:"source,omitempty"`
+	Submitter string          `json:"submitter,omitempty"`
 }
 
 type SignatureResponse AllTypes[map[string][]*SignatureData]
@@ -58,8 +122,184 @@ func NewSignatureResponse() SignatureResponse {
 	return response
 }
 
+type SearchResult struct {
+	Hash string `json:"hash"`
+	*SignatureData
+}
+
+type SearchResponse struct {
+	Results    AllTypes[[]*SearchResult] `json:"results"`
+	NextCursor string                    `json:"next_cursor,omitempty"`
+}
+
+func NewSearchResponse() *SearchResponse {
+	response := &SearchResponse{
+		Results: make(AllTypes[[]*SearchResult]),
+	}
+	for _, typ := range SignatureTypes() {
+		response.Results[typ] = []*SearchResult{}
+	}
+	return response
+}
+
+// CanonicalSignature is the only function signature which is expected to be seen for its hash
+type CanonicalSignature struct {
+	Hash      string    `json:"hash"`
+	Signature string    `json:"signature"`
+	Source    string    `json:"source,omitempty"`
+	Manual    bool      `json:"manual"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DecodeRequest holds either calldata, which may also be revert data, or the topics and data of a log
+type DecodeRequest struct {
+	Calldata string   `json:"calldata,omitempty"`
+	Topics   []string `json:"topics,omitempty"`
+	Data     string   `json:"data,omitempty"`
+}
+
+// DecodedArgument is a decoded value. Integers are decimal strings, bytes and addresses are hex, arrays are lists and
+// tuples are lists of DecodedArgument. Indexed event parameters of dynamic types are only available as their hash
+type DecodedArgument struct {
+	Type    string `json:"type"`
+	Value   any    `json:"value"`
+	Indexed bool   `json:"indexed,omitempty"`
+	Hashed  bool   `json:"hashed,omitempty"`
+}
+
+type DecodedSignature struct {
+	Type      SignatureType      `json:"type"`
+	Hash      string             `json:"hash"`
+	Name      string             `json:"name"`
+	Filtered  bool               `json:"filtered"`
+	Arguments []*DecodedArgument `json:"arguments"`
+}
+
+type DecodeResponse struct {
+	// Candidates is how many known signatures were tried, whether or not they decoded
+	Candidates int `json:"candidates"`
+	// Skipped is how many known signatures weren't tried as there were too many candidates, the best scored are tried
+	Skipped int                 `json:"skipped,omitempty"`
+	Results []*DecodedSignature `json:"results"`
+}
+
+type CollisionClassification string
+
+const (
+	CollisionBenign CollisionClassification = "benign"
+	// CollisionSuspicious is a new name colliding with a canonical or popular one, as in a selector clash attack
+	CollisionSuspicious CollisionClassification = "suspicious"
+)
+
+func (c CollisionClassification) Valid() bool {
+	return c == CollisionBenign || c == CollisionSuspicious
+}
+
+// Collision is a hash shared by more than one signature
+type Collision struct {
+	Type           SignatureType           `json:"type"`
+	Hash           string                  `json:"hash"`
+	Classification CollisionClassification `json:"classification"`
+	Reason         string                  `json:"reason,omitempty"`
+	Names          []*SignatureData        `json:"names"`
+	DetectedAt     time.Time               `json:"detected_at"`
+	UpdatedAt      time.Time               `json:"updated_at"`
+}
+
+type CollisionsResponse struct {
+	Collisions []*Collision `json:"collisions"`
+}
+
+// Key describes an api key, without its secret
+type Key struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Role      string     `json:"role"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+type CreateKeyRequest struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+type CreateKeyResponse struct {
+	ID string `json:"id"`
+	// Token is only ever returned when the key is created, and should be presented as a bearer token
+	Token string `json:"token"`
+}
+
+type ExportFormat string
+
+const (
+	// ExportFormatText is the original "0xhash,name" format, with no way to tell the types apart
+	ExportFormatText   ExportFormat = "txt"
+	ExportFormatCSV    ExportFormat = "csv"
+	ExportFormatNDJSON ExportFormat = "ndjson"
+)
+
+func (f ExportFormat) Valid() bool {
+	return f == ExportFormatText || f == ExportFormatCSV || f == ExportFormatNDJSON
+}
+
+// ExportedSignature is a single row of an export. IDs increase as signatures are added, across every type
+type ExportedSignature struct {
+	ID   int64         `json:"id"`
+	Type SignatureType `json:"type"`
+	Hash string        `json:"hash"`
+	Name string        `json:"name"`
+	// Removed is set in incremental exports for signatures which an audit found had the wrong hash, and which should be
+	// dropped. A repaired signature is removed with its old hash and exported again with the right one
+	Removed bool `json:"removed,omitempty"`
+}
+
+// ExportTrailer is the last line of an ndjson export, which is only written once every row has been. Csv exports end
+// with a "#end,<count>,," row instead, and txt exports have no trailer
+type ExportTrailer struct {
+	End   bool  `json:"end"`
+	Count int64 `json:"count"`
+}
+
+type AuditMode string
+
+const (
+	// AuditModeFlag only reports mismatched rows
+	AuditModeFlag AuditMode = "flag"
+	// AuditModeRepair recomputes the hash of mismatched rows from their name
+	AuditModeRepair AuditMode = "repair"
+	// AuditModeQuarantine moves mismatched rows out of the way, into the signature_quarantine table
+	AuditModeQuarantine AuditMode = "quarantine"
+)
+
+func (m AuditMode) Valid() bool {
+	return m == AuditModeFlag || m == AuditModeRepair || m == AuditModeQuarantine
+}
+
+type AuditMismatch struct {
+	Type     SignatureType `json:"type"`
+	Name     string        `json:"name"`
+	Hash     string        `json:"hash"`
+	Expected string        `json:"expected"`
+}
+
+// AuditReport is the outcome of recomputing the hash of every stored signature
+type AuditReport struct {
+	Mode       AuditMode       `json:"mode"`
+	StartedAt  time.Time       `json:"started_at"`
+	FinishedAt time.Time       `json:"finished_at"`
+	Checked    AllTypes[int64] `json:"checked"`
+	Mismatched AllTypes[int64] `json:"mismatched"`
+	// Fixed is the number of mismatched rows which were repaired or quarantined
+	Fixed AllTypes[int64] `json:"fixed"`
+	// Mismatches holds the first few mismatched rows found
+	Mismatches []*AuditMismatch `json:"mismatches"`
+}
+
 type StatsResponse struct {
 	Count AllTypes[int] `json:"count"`
+	// Audit is the latest consistency audit, if one has run
+	Audit *AuditReport `json:"audit,omitempty"`
 }
 
 func NewStatsResponse() *StatsResponse {
@@ -0,0 +1,248 @@
+package signature_database_srv
+
+import (
+	"context"
+	"fmt"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/core"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/notify"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/client"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/database"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultCollisionsLimit = 100
+	maxCollisionsLimit     = 1000
+
+	collisionScanBatchSize = 1000
+)
+
+// classifyCollision decides whether the names sharing a hash look accidental. A collision is suspicious when its
+// newest name isn't the canonical signature but arrived after it, or after a name which has been seen in the wild
+// while the newcomer hasn't. That's what a selector clash crafted to impersonate a well known function looks like.
+// Being seen in the wild is judged by score, which only keys with the observer role can raise.
+func classifyCollision(names []*client.SignatureData, canonical string, popularScore int64) (client.CollisionClassification, string) {
+	var newest *client.SignatureData
+	for _, name := range names {
+		if newest == nil || (name.CreatedAt != nil && (newest.CreatedAt == nil || name.CreatedAt.After(*newest.CreatedAt))) {
+			newest = name
+		}
+	}
+	if newest == nil {
+		return client.CollisionBenign, ""
+	}
+
+	if canonical != "" {
+		if newest.Name == canonical {
+			return client.CollisionBenign, ""
+		}
+		return client.CollisionSuspicious, fmt.Sprintf("%s collides with canonical signature %s", newest.Name, canonical)
+	}
+
+	if newest.Score >= popularScore {
+		return client.CollisionBenign, ""
+	}
+	for _, name := range names {
+		if name != newest && name.Score >= popularScore {
+			return client.CollisionSuspicious, fmt.Sprintf("%s collides with popular signature %s", newest.Name, name.Name)
+		}
+	}
+
+	return client.CollisionBenign, ""
+}
+
+// checkCollisions classifies and records every hash of the given type which has more than one name. New and changed
+// suspicious collisions are sent to the notifiers, except that rescans only send those which gained a name since they
+// were last saved, rather than those which were only reclassified or lost a name, such as after the canonical signatures
+// changed or an audit repaired a signature
+func (s *Service) checkCollisions(typ client.SignatureType, sigs map[string][]*client.SignatureData, rescan bool) error {
+	for hash, names := range sigs {
+		if len(names) <= 1 {
+			continue
+		}
+
+		var canonical string
+		if typ == client.SignatureTypeFunction {
+			s.canonicalSignaturesLock.RLock()
+			canonical = s.canonicalSignatures[hash]
+			s.canonicalSignaturesLock.RUnlock()
+		}
+
+		collision := &client.Collision{
+			Type:  typ,
+			Hash:  hash,
+			Names: names,
+		}
+		collision.Classification, collision.Reason = classifyCollision(names, canonical, s.config.CollisionPopularScore)
+
+		changed, previousNames, err := s.db.SaveCollision(collision)
+		if err != nil {
+			return fmt.Errorf("failed to save collision for %s: %w", hash, err)
+		}
+
+		if rescan && previousNames != nil && !gainedName(names, previousNames) {
+			continue
+		}
+		if changed && collision.Classification == client.CollisionSuspicious {
+			s.notifyCollision(collision)
+		}
+	}
+
+	return nil
+}
+
+// gainedName returns whether any of the names wasn't among the previous ones
+func gainedName(names []*client.SignatureData, previous []string) bool {
+	seen := make(map[string]bool, len(previous))
+	for _, name := range previous {
+		seen[name] = true
+	}
+	for _, name := range names {
+		if !seen[name.Name] {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Service) notifyCollision(collision *client.Collision) {
+	var names []string
+	for _, name := range collision.Names {
+		names = append(names, fmt.Sprintf("`%s`", name.Name))
+	}
+
+	_ = s.notifier.Notify(context.Background(), &notify.Message{
+		Event: notify.EventCollision,
+		Title: fmt.Sprintf("Suspicious %s collision on %s", collision.Type, collision.Hash),
+		Text:  fmt.Sprintf("%s\n%s", collision.Reason, strings.Join(names, ", ")),
+		Data:  collision,
+	})
+}
+
+// runCollisionScans periodically records every existing collision, catching those which predate detection or whose
+// classification changed with the canonical signatures. It's also what finds collisions brought in by bulk imports and
+// syncing, which don't go through checkImported, so collisions which are new to the scan are alerted on
+func (s *Service) runCollisionScans() {
+	ticker := time.NewTicker(24 * time.Hour)
+	for ; true; <-ticker.C {
+		for _, typ := range client.SignatureTypes() {
+			if err := s.scanCollisions(typ); err != nil {
+				log.WithError(err).WithField("type", typ).Errorf("failed to scan for collisions")
+			}
+		}
+	}
+}
+
+func (s *Service) scanCollisions(typ client.SignatureType) error {
+	hashes, err := s.db.FindCollidingHashes(typ)
+	if err != nil {
+		return fmt.Errorf("failed to find colliding hashes: %w", err)
+	}
+
+	for start := 0; start < len(hashes); start += collisionScanBatchSize {
+		end := start + collisionScanBatchSize
+		if end > len(hashes) {
+			end = len(hashes)
+		}
+
+		sigs, err := s.db.LoadSignatures(typ, hashes[start:end])
+		if err != nil {
+			return fmt.Errorf("failed to load signatures: %w", err)
+		}
+
+		if err := s.checkCollisions(typ, sigs, true); err != nil {
+			return err
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"type":       typ,
+		"collisions": len(hashes),
+	}).Infof("scanned for collisions")
+
+	return nil
+}
+
+func (s *Service) serveCollisions(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	filter := &database.CollisionFilter{
+		Type:           client.SignatureType(params.Get("type")),
+		Classification: client.CollisionClassification(params.Get("classification")),
+		Hash:           params.Get("hash"),
+	}
+
+	if filter.Type != "" && !filter.Type.Valid() {
+		fail(w, http.StatusBadRequest, nil, "type must be one of function, event or error")
+		return
+	}
+	if filter.Classification != "" && !filter.Classification.Valid() {
+		fail(w, http.StatusBadRequest, nil, "classification must be one of benign or suspicious")
+		return
+	}
+
+	if params.Has("since") {
+		since, err := time.Parse(time.RFC3339, params.Get("since"))
+		if err != nil {
+			fail(w, http.StatusBadRequest, err, "since must be an RFC 3339 timestamp")
+			return
+		}
+		filter.Since = since
+	}
+
+	limit := defaultCollisionsLimit
+	if params.Has("limit") {
+		var err error
+		limit, err = strconv.Atoi(params.Get("limit"))
+		if err != nil || limit <= 0 || limit > maxCollisionsLimit {
+			fail(w, http.StatusBadRequest, err, fmt.Sprintf("limit must be between 1 and %d", maxCollisionsLimit))
+			return
+		}
+	}
+
+	var offset int
+	if params.Has("offset") {
+		var err error
+		offset, err = strconv.Atoi(params.Get("offset"))
+		if err != nil || offset < 0 {
+			fail(w, http.StatusBadRequest, err, "offset must be a non-negative integer")
+			return
+		}
+	}
+
+	collisions, err := s.db.QueryCollisions(filter, limit, offset)
+	if err != nil {
+		fail(w, http.StatusInternalServerError, err, "failed to query collisions")
+		return
+	}
+
+	byType := make(map[client.SignatureType][]string)
+	for _, collision := range collisions {
+		byType[collision.Type] = append(byType[collision.Type], collision.Hash)
+	}
+	for typ, hashes := range byType {
+		sigs, err := s.db.LoadSignatures(typ, hashes)
+		if err != nil {
+			fail(w, http.StatusInternalServerError, err, "failed to load signatures")
+			return
+		}
+		for _, collision := range collisions {
+			if collision.Type == typ {
+				collision.Names = sigs[collision.Hash]
+			}
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"ip":             core.GetRemoteIP(r),
+		"ua":             core.GetUserAgent(r),
+		"type":           filter.Type,
+		"classification": filter.Classification,
+		"results":        len(collisions),
+	}).Infof("listed collisions")
+
+	succeed(w, &client.CollisionsResponse{Collisions: collisions})
+}
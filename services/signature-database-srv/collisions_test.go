@@ -0,0 +1,133 @@
+package signature_database_srv
+
+import (
+	"context"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/auth"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/client"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func Test_ClassifyCollision(t *testing.T) {
+	at := func(days int) *time.Time {
+		t := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, days)
+		return &t
+	}
+
+	tests := []struct {
+		name      string
+		names     []*client.SignatureData
+		canonical string
+		expected  client.CollisionClassification
+	}{
+		{
+			name: "new name against canonical",
+			names: []*client.SignatureData{
+				{Name: "transfer(address,uint256)", CreatedAt: at(0)},
+				{Name: "many_msg_babbage(bytes1)", CreatedAt: at(10)},
+			},
+			canonical: "transfer(address,uint256)",
+			expected:  client.CollisionSuspicious,
+		},
+		{
+			name: "canonical arriving last",
+			names: []*client.SignatureData{
+				{Name: "many_msg_babbage(bytes1)", CreatedAt: at(0)},
+				{Name: "transfer(address,uint256)", CreatedAt: at(10)},
+			},
+			canonical: "transfer(address,uint256)",
+			expected:  client.CollisionBenign,
+		},
+		{
+			name: "new name against popular",
+			names: []*client.SignatureData{
+				{Name: "popular()", Score: 5, CreatedAt: at(0)},
+				{Name: "impostor_1234()", CreatedAt: at(10)},
+			},
+			expected: client.CollisionSuspicious,
+		},
+		{
+			name: "both unobserved",
+			names: []*client.SignatureData{
+				{Name: "a()", CreatedAt: at(0)},
+				{Name: "b()", CreatedAt: at(10)},
+			},
+			expected: client.CollisionBenign,
+		},
+		{
+			name: "new name observed on chain",
+			names: []*client.SignatureData{
+				{Name: "a()", Score: 5, CreatedAt: at(0)},
+				{Name: "b()", Score: 1, CreatedAt: at(10)},
+			},
+			expected: client.CollisionBenign,
+		},
+		{
+			name: "missing metadata",
+			names: []*client.SignatureData{
+				{Name: "a()", Score: 5, CreatedAt: at(0)},
+				{Name: "b()"},
+			},
+			expected: client.CollisionBenign,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			classification, reason := classifyCollision(test.names, test.canonical, 1)
+			assert.Equal(t, test.expected, classification)
+			assert.Equal(t, classification == client.CollisionSuspicious, reason != "")
+		})
+	}
+}
+
+func Test_ImportMetadataObserved(t *testing.T) {
+	authenticator := auth.New()
+	withRole := func(role auth.Role) context.Context {
+		ctx, err := authenticator.Authorize(context.Background(), &auth.Key{Name: "key", Role: role}, auth.RoleImporter)
+		assert.NoError(t, err)
+		return ctx
+	}
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		source   client.SignatureSource
+		observed bool
+	}{
+		{"anonymous source", context.Background(), client.SignatureSourceCompiled, false},
+		{"importer source", withRole(auth.RoleImporter), client.SignatureSourceCompiled, false},
+		{"verified source", withRole(auth.RoleObserver), client.SignatureSourceCompiled, true},
+		{"observer abi", withRole(auth.RoleObserver), client.SignatureSourceABI, false},
+		{"on-chain", withRole(auth.RoleObserver), client.SignatureSourceOnChain, true},
+		{"anonymous on-chain", context.Background(), client.SignatureSourceOnChain, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			meta, err := newImportMetadata(test.ctx, "", test.source)
+			assert.NoError(t, err)
+			assert.Equal(t, test.observed, meta.Observed)
+		})
+	}
+}
+
+func Test_GainedName(t *testing.T) {
+	names := []*client.SignatureData{{Name: "a()"}, {Name: "c()"}}
+
+	tests := []struct {
+		name     string
+		previous []string
+		gained   bool
+	}{
+		{"unchanged", []string{"a()", "c()"}, false},
+		{"lost a name", []string{"a()", "b()", "c()"}, false},
+		{"added a name", []string{"a()"}, true},
+		{"replaced a name", []string{"a()", "b()"}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.gained, gainedName(names, test.previous))
+		})
+	}
+}
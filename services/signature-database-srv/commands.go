@@ -0,0 +1,297 @@
+package signature_database_srv
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/auth"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/discord"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/ratelimit"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/client"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/database"
+	log "github.com/sirupsen/logrus"
+	"strings"
+	"time"
+)
+
+// how many search results fit comfortably in a discord message
+const commandSearchLimit = 25
+
+var signatureTypeChoices = []*discordgo.ApplicationCommandOptionChoice{
+	{Name: "function", Value: string(client.SignatureTypeFunction)},
+	{Name: "event", Value: string(client.SignatureTypeEvent)},
+	{Name: "error", Value: string(client.SignatureTypeError)},
+}
+
+// discordCommands are the slash commands the bot answers. Results are filtered against the canonical signatures just
+// like the api, so what's shown in discord matches the website. Discord users have no api key, so only the commands
+// which the anonymous role may use are registered, along with /import if some guilds or roles are trusted with it.
+// Each discord user is rate limited like an anonymous api client
+func (s *Service) discordCommands() []*discord.Command {
+	anonymousRole := auth.Role(s.config.AnonymousRole)
+	trustedImporters := len(s.config.DiscordImportGuilds) > 0 || len(s.config.DiscordImportRoles) > 0
+
+	var commands []*discord.Command
+	for _, command := range s.allDiscordCommands() {
+		if anonymousRole.Includes(command.role) || (command.role == auth.RoleImporter && trustedImporters) {
+			commands = append(commands, s.guardDiscordCommand(command))
+		}
+	}
+	return commands
+}
+
+// guardDiscordCommand checks that whoever ran a command may use it, and charges it to their rate limit
+func (s *Service) guardDiscordCommand(command *roleCommand) *discord.Command {
+	return &discord.Command{
+		Command: command.Command.Command,
+		Handler: func(ctx context.Context, i *discordgo.InteractionCreate, options map[string]string) (string, error) {
+			if !s.discordAllowed(command.role, i) {
+				return "You aren't allowed to use this command here", nil
+			}
+
+			if s.limiter != nil {
+				bucket, limit := s.discordBucket(i)
+				ok, wait, err := s.limiter.Allow(ctx, bucket, limit)
+				if err != nil {
+					log.WithError(err).Warnf("failed to check rate limit")
+				} else if !ok {
+					return fmt.Sprintf("Slow down, try again in %s", wait.Round(time.Second)), nil
+				}
+			}
+
+			return command.Handler(ctx, i, options)
+		},
+	}
+}
+
+// discordBucket returns the rate limit bucket a discord user is charged to, and its limit
+func (s *Service) discordBucket(i *discordgo.InteractionCreate) (string, ratelimit.Limit) {
+	return "discord:" + discord.InteractionUser(i).ID, s.ipLimit()
+}
+
+// discordAllowed returns whether a discord user may run a command needing the role. That's anyone if the anonymous
+// role includes it, while importing may also be granted to the members of some guilds, or those with some roles
+func (s *Service) discordAllowed(role auth.Role, i *discordgo.InteractionCreate) bool {
+	if auth.Role(s.config.AnonymousRole).Includes(role) {
+		return true
+	}
+	if role != auth.RoleImporter {
+		return false
+	}
+
+	for _, guild := range s.config.DiscordImportGuilds {
+		if i.GuildID != "" && i.GuildID == guild {
+			return true
+		}
+	}
+	if i.Member != nil {
+		for _, memberRole := range i.Member.Roles {
+			for _, trusted := range s.config.DiscordImportRoles {
+				if memberRole == trusted {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+type roleCommand struct {
+	*discord.Command
+	role auth.Role
+}
+
+func (s *Service) allDiscordCommands() []*roleCommand {
+	return []*roleCommand{
+		{role: auth.RoleReadOnly, Command: &discord.Command{
+			Command: &discordgo.ApplicationCommand{
+				Name:        "lookup",
+				Description: "Look up the signatures for a selector or event topic",
+				Options: []*discordgo.ApplicationCommandOption{
+					{Type: discordgo.ApplicationCommandOptionString, Name: "hash", Description: "A 4 byte selector or 32 byte topic", Required: true},
+				},
+			},
+			Handler: s.commandLookup,
+		}},
+		{role: auth.RoleReadOnly, Command: &discord.Command{
+			Command: &discordgo.ApplicationCommand{
+				Name:        "search",
+				Description: "Search signatures by name, with * and ? wildcards",
+				Options: []*discordgo.ApplicationCommandOption{
+					{Type: discordgo.ApplicationCommandOptionString, Name: "query", Description: "A name such as transfer*", Required: true},
+					{Type: discordgo.ApplicationCommandOptionString, Name: "type", Description: "Only search one type of signature", Choices: signatureTypeChoices},
+				},
+			},
+			Handler: s.commandSearch,
+		}},
+		{role: auth.RoleImporter, Command: &discord.Command{
+			Command: &discordgo.ApplicationCommand{
+				Name:        "import",
+				Description: "Import a signature or declaration",
+				Options: []*discordgo.ApplicationCommandOption{
+					{Type: discordgo.ApplicationCommandOptionString, Name: "signature", Description: "A signature such as transfer(address,uint256)", Required: true},
+					{Type: discordgo.ApplicationCommandOptionString, Name: "type", Description: "Defaults to function", Choices: signatureTypeChoices},
+				},
+			},
+			Handler: s.commandImport,
+		}},
+		{role: auth.RoleReadOnly, Command: &discord.Command{
+			Command: &discordgo.ApplicationCommand{
+				Name:        "decode",
+				Description: "Decode calldata or revert data using the known signatures for its selector",
+				Options: []*discordgo.ApplicationCommandOption{
+					{Type: discordgo.ApplicationCommandOptionString, Name: "calldata", Description: "Hex encoded calldata", Required: true},
+				},
+			},
+			Handler: s.commandDecode,
+		}},
+	}
+}
+
+func (s *Service) commandLookup(ctx context.Context, i *discordgo.InteractionCreate, options map[string]string) (string, error) {
+	hash := strings.ToLower(strings.TrimSpace(options["hash"]))
+
+	var types []client.SignatureType
+	switch len(hash) {
+	case 2 + 2*4:
+		types = []client.SignatureType{client.SignatureTypeFunction, client.SignatureTypeError}
+	case 2 + 2*32:
+		types = []client.SignatureType{client.SignatureTypeEvent}
+	default:
+		return fmt.Sprintf("`%s` is neither a 4 byte selector nor a 32 byte topic", hash), nil
+	}
+	if _, err := hexutil.Decode(hash); err != nil {
+		return fmt.Sprintf("`%s` is not valid hex", hash), nil
+	}
+
+	response := client.NewSignatureResponse()
+	for _, typ := range types {
+		sigs, err := s.db.LoadSignatures(typ, []string{hash})
+		if err != nil {
+			return "", fmt.Errorf("failed to load signatures: %w", err)
+		}
+		response[typ] = sigs
+	}
+	s.filterResponse(response, true)
+
+	var lines []string
+	for _, typ := range types {
+		for _, sig := range response[typ][hash] {
+			lines = append(lines, fmt.Sprintf("%s `%s`", typ, sig.Name))
+		}
+	}
+	if len(lines) == 0 {
+		return fmt.Sprintf("No signatures found for `%s`", hash), nil
+	}
+
+	return fmt.Sprintf("Signatures for `%s`:\n%s", hash, strings.Join(lines, "\n")), nil
+}
+
+func (s *Service) commandSearch(ctx context.Context, i *discordgo.InteractionCreate, options map[string]string) (string, error) {
+	filter := &database.SearchFilter{
+		Query: options["query"],
+		Type:  client.SignatureType(options["type"]),
+	}
+
+	response, err := s.db.QuerySignatures(filter, "", commandSearchLimit)
+	if errors.Is(err, database.ErrInvalidSearch) {
+		return "Invalid query: " + strings.TrimPrefix(err.Error(), database.ErrInvalidSearch.Error()+": "), nil
+	} else if err != nil {
+		return "", err
+	}
+	s.filterSearchResponse(response, true)
+
+	var lines []string
+	for _, typ := range client.SignatureTypes() {
+		for _, result := range response.Results[typ] {
+			lines = append(lines, fmt.Sprintf("%s `%s` `%s`", typ, result.Hash, result.Name))
+		}
+	}
+	if len(lines) == 0 {
+		return fmt.Sprintf("No signatures matching `%s`", filter.Query), nil
+	}
+	if response.NextCursor != "" {
+		lines = append(lines, "...and more, narrow the query to see them")
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+func (s *Service) commandImport(ctx context.Context, i *discordgo.InteractionCreate, options map[string]string) (string, error) {
+	typ := client.SignatureTypeFunction
+	if options["type"] != "" {
+		typ = client.SignatureType(options["type"])
+	}
+
+	response, err := s.importRaw(client.ImportRequest{
+		typ: {options["signature"]},
+	}, &database.SignatureMetadata{
+		Source:    client.SignatureSourceImport,
+		Submitter: "discord:" + discord.InteractionUser(i).String(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	details := response[typ]
+	var lines []string
+	for name, hash := range details.Imported {
+		lines = append(lines, fmt.Sprintf("Imported `%s` as `%s`", name, hash))
+	}
+	for name, hash := range details.Duplicated {
+		lines = append(lines, fmt.Sprintf("`%s` was already known as `%s`", name, hash))
+	}
+	for _, name := range details.Invalid {
+		lines = append(lines, fmt.Sprintf("`%s` is not a valid %s signature", name, typ))
+	}
+	for text, sig := range details.Rewritten {
+		lines = append(lines, fmt.Sprintf("Normalized `%s` to `%s`", text, sig))
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+func (s *Service) commandDecode(ctx context.Context, i *discordgo.InteractionCreate, options map[string]string) (string, error) {
+	bucket, limit := s.discordBucket(i)
+	response, err := s.decode(&client.DecodeRequest{
+		Calldata: strings.TrimSpace(options["calldata"]),
+	}, true, func(work int) error {
+		return s.chargeWork(ctx, bucket, limit, work)
+	})
+	var limited *rateLimitedError
+	if errors.As(err, &limited) {
+		return fmt.Sprintf("Slow down, try again in %s", limited.wait.Round(time.Second)), nil
+	} else if errors.Is(err, errInvalidDecodeRequest) {
+		return "Invalid calldata: " + strings.TrimPrefix(err.Error(), errInvalidDecodeRequest.Error()+": "), nil
+	} else if err != nil {
+		return "", err
+	}
+
+	if response.Candidates == 0 {
+		return "No signatures found for this selector", nil
+	}
+	if len(response.Results) == 0 && response.Skipped > 0 {
+		return fmt.Sprintf("The calldata doesn't decode as any of the %d best scored of the %d known signatures for its selector", response.Candidates, response.Candidates+response.Skipped), nil
+	}
+	if len(response.Results) == 0 {
+		return fmt.Sprintf("The calldata doesn't decode as any of the %d known signatures for its selector", response.Candidates), nil
+	}
+
+	var parts []string
+	for _, result := range response.Results {
+		lines := []string{fmt.Sprintf("%s `%s`", result.Type, result.Name)}
+		for idx, arg := range result.Arguments {
+			b, err := json.Marshal(arg.Value)
+			if err != nil {
+				return "", err
+			}
+			lines = append(lines, fmt.Sprintf("%d. %s `%s`", idx, arg.Type, b))
+		}
+		parts = append(parts, strings.Join(lines, "\n"))
+	}
+
+	return strings.Join(parts, "\n\n"), nil
+}
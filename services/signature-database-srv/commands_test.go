@@ -0,0 +1,55 @@
+package signature_database_srv
+
+import (
+	"context"
+	"github.com/bwmarrin/discordgo"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/auth"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/discord"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_DiscordCommands(t *testing.T) {
+	s := &Service{
+		config: &Config{
+			AnonymousRole:       string(auth.RoleReadOnly),
+			DiscordImportGuilds: []string{"trusted-guild"},
+			DiscordImportRoles:  []string{"trusted-role"},
+			IPRateLimit:         1,
+			IPRateBurst:         1,
+		},
+		limiter: ratelimit.NewMemoryLimiter(),
+	}
+
+	var names []string
+	for _, command := range s.discordCommands() {
+		names = append(names, command.Command.Name)
+	}
+	assert.Equal(t, []string{"lookup", "search", "import", "decode"}, names)
+
+	run := func(role auth.Role, user string, guild string, roles ...string) string {
+		command := s.guardDiscordCommand(&roleCommand{role: role, Command: &discord.Command{
+			Command: &discordgo.ApplicationCommand{Name: "test"},
+			Handler: func(ctx context.Context, i *discordgo.InteractionCreate, options map[string]string) (string, error) {
+				return "ran", nil
+			},
+		}})
+		reply, err := command.Handler(context.Background(), &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+			GuildID: guild,
+			Member:  &discordgo.Member{User: &discordgo.User{ID: user}, Roles: roles},
+		}}, nil)
+		assert.NoError(t, err)
+		return reply
+	}
+
+	assert.Equal(t, "ran", run(auth.RoleReadOnly, "reader", "other-guild"))
+	assert.Equal(t, "ran", run(auth.RoleImporter, "member", "trusted-guild"))
+	assert.Equal(t, "ran", run(auth.RoleImporter, "holder", "other-guild", "trusted-role"))
+	assert.Equal(t, "You aren't allowed to use this command here", run(auth.RoleImporter, "stranger", "other-guild", "other-role"))
+	assert.Equal(t, "You aren't allowed to use this command here", run(auth.RoleAdmin, "member", "trusted-guild"))
+
+	// each user has their own limit
+	assert.Contains(t, run(auth.RoleReadOnly, "reader", "other-guild"), "Slow down")
+	assert.Equal(t, "ran", run(auth.RoleReadOnly, "someone else", "other-guild"))
+}
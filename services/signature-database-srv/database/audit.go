@@ -0,0 +1,252 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/jackc/pgx/v5"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/database"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/client"
+	"time"
+)
+
+// maxAuditSamples is how many mismatched rows are kept in a report
+const maxAuditSamples = 100
+
+// auditPageSize is how many signatures are checked at a time
+const auditPageSize = 10000
+
+var (
+	// ErrAuditRunning is returned when an audit is already running, possibly on another replica
+	ErrAuditRunning = errors.New("an audit is already running")
+	// ErrAuditNotDue is returned when an audit has already been started more recently than the interval asked for
+	ErrAuditNotDue = errors.New("an audit has already been run recently")
+)
+
+type auditMismatch struct {
+	name     string
+	hash     []byte
+	expected []byte
+}
+
+// AuditSignatures recomputes the hash of every stored signature, fixing mismatched rows according to mode. Only one
+// audit runs at a time across every replica, and if interval is set, it returns ErrAuditNotDue rather than auditing
+// again when the last audit started less than interval ago. Fixed rows are recorded as removed and re-added, so that
+// incremental exports pick up the fix
+func (d *Database) AuditSignatures(mode client.AuditMode, interval time.Duration) (*client.AuditReport, error) {
+	conn, err := d.db.Acquire(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Release()
+
+	// the lock belongs to the session, so it has to be released on the connection which took it
+	var locked bool
+	if err := conn.QueryRow(context.Background(), `SELECT pg_try_advisory_lock(hashtext('signature_audit'))`).Scan(&locked); err != nil {
+		return nil, fmt.Errorf("failed to lock audit: %w", err)
+	}
+	if !locked {
+		return nil, ErrAuditRunning
+	}
+	defer func() {
+		if _, err := conn.Exec(context.Background(), `SELECT pg_advisory_unlock(hashtext('signature_audit'))`); err != nil {
+			conn.Hijack().Close(context.Background())
+		}
+	}()
+
+	if interval > 0 {
+		last, err := d.LatestAuditReport()
+		if err != nil {
+			return nil, err
+		}
+		if last != nil && time.Since(last.StartedAt) < interval {
+			return nil, ErrAuditNotDue
+		}
+	}
+
+	report := &client.AuditReport{
+		Mode:       mode,
+		StartedAt:  time.Now(),
+		Checked:    make(client.AllTypes[int64]),
+		Mismatched: make(client.AllTypes[int64]),
+		Fixed:      make(client.AllTypes[int64]),
+		Mismatches: []*client.AuditMismatch{},
+	}
+
+	for _, typ := range client.SignatureTypes() {
+		if err := d.auditType(typ, mode, report); err != nil {
+			return nil, err
+		}
+	}
+
+	report.FinishedAt = time.Now()
+
+	encoded, err := json.Marshal(report)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := d.db.Exec(context.Background(), `INSERT INTO audit_report (mode, started_at, finished_at, report) VALUES ($1, $2, $3, $4)`,
+		string(mode), report.StartedAt, report.FinishedAt, encoded); err != nil {
+		return nil, fmt.Errorf("failed to save report: %w", err)
+	}
+
+	return report, nil
+}
+
+// auditType checks the signatures of a type a page at a time, fixing each page's mismatches before moving on, so that
+// neither the table nor its mismatches have to fit in memory
+func (d *Database) auditType(typ client.SignatureType, mode client.AuditMode, report *client.AuditReport) error {
+	// repaired rows are given new ids, so stop at the last row which existed when the audit started rather than checking
+	// them again
+	var upTo int64
+	if err := d.db.QueryRowSimple(func(row pgx.Row) error {
+		return row.Scan(&upTo)
+	}, fmt.Sprintf(`SELECT COALESCE(MAX(id), 0) FROM %s`, exportTables[typ])); err != nil {
+		return fmt.Errorf("failed to audit %s signatures: %w", typ, err)
+	}
+
+	var after int64
+	for {
+		mismatches, checked, last, err := d.findMismatches(typ, after, upTo)
+		if err != nil {
+			return fmt.Errorf("failed to audit %s signatures: %w", typ, err)
+		}
+		report.Checked[typ] += checked
+		report.Mismatched[typ] += int64(len(mismatches))
+
+		for _, m := range mismatches {
+			if len(report.Mismatches) == maxAuditSamples {
+				break
+			}
+			report.Mismatches = append(report.Mismatches, &client.AuditMismatch{
+				Type:     typ,
+				Name:     m.name,
+				Hash:     hexutil.Encode(m.hash),
+				Expected: hexutil.Encode(m.expected),
+			})
+		}
+
+		if len(mismatches) > 0 {
+			var fixed int64
+			switch mode {
+			case client.AuditModeRepair:
+				fixed, err = d.repairMismatches(typ, mismatches)
+			case client.AuditModeQuarantine:
+				fixed, err = d.quarantineMismatches(typ, mismatches)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to %s %s signatures: %w", mode, typ, err)
+			}
+			report.Fixed[typ] += fixed
+		}
+
+		if checked < auditPageSize {
+			return nil
+		}
+		after = last
+	}
+}
+
+// findMismatches checks a page of signatures with ids greater than after and at most upTo, returning the last id it
+// checked. Pages are keyed by id rather than offset, which quarantines would shift
+func (d *Database) findMismatches(typ client.SignatureType, after int64, upTo int64) ([]*auditMismatch, int64, int64, error) {
+	var (
+		mismatches []*auditMismatch
+		checked    int64
+		last       int64
+	)
+
+	if err := d.db.QuerySimple(func(r pgx.Rows) error {
+		var (
+			name string
+			hash []byte
+		)
+		for r.Next() {
+			if err := r.Scan(&last, &name, &hash); err != nil {
+				return err
+			}
+			checked++
+
+			expected := crypto.Keccak256([]byte(name))[:typ.HashLength()]
+			if string(expected) != string(hash) {
+				mismatches = append(mismatches, &auditMismatch{name: name, hash: hash, expected: expected})
+			}
+		}
+		return nil
+	}, fmt.Sprintf(`SELECT id, name, hash FROM %s WHERE id > $1 AND id <= $2 ORDER BY id LIMIT $3`, exportTables[typ]), after, upTo, auditPageSize); err != nil {
+		return nil, 0, 0, err
+	}
+
+	return mismatches, checked, last, nil
+}
+
+// repairMismatches records the row with its wrong hash as removed, then gives it the right hash and a new id, so that
+// it's exported again
+func (d *Database) repairMismatches(typ client.SignatureType, mismatches []*auditMismatch) (int64, error) {
+	var fixed int64
+
+	err := d.db.ExecTx(func(tx *database.Tx) error {
+		// new ids are handed out under the same lock as inserts, for ExportVersion
+		if _, err := tx.Exec(context.Background(), `SELECT pg_advisory_xact_lock_shared('signature_id_seq'::regclass::oid::bigint)`); err != nil {
+			return err
+		}
+
+		return tx.ExecBatch(func(stmt *database.Stmt) error {
+			for _, m := range mismatches {
+				res, err := stmt.Exec(context.Background(), string(typ), m.name, m.expected)
+				if err != nil {
+					return err
+				}
+				fixed += res.RowsAffected()
+			}
+			return nil
+		}, fmt.Sprintf(`WITH removed AS (INSERT INTO signature_removal (type, name, hash) SELECT $1, name, hash FROM %s WHERE name = $2 AND hash <> $3)
+			UPDATE %s SET hash = $3, id = nextval('signature_id_seq') WHERE name = $2 AND hash <> $3`, exportTables[typ], exportTables[typ]))
+	})
+
+	return fixed, err
+}
+
+func (d *Database) quarantineMismatches(typ client.SignatureType, mismatches []*auditMismatch) (int64, error) {
+	var fixed int64
+
+	err := d.db.ExecTx(func(tx *database.Tx) error {
+		return tx.ExecBatch(func(stmt *database.Stmt) error {
+			for _, m := range mismatches {
+				res, err := stmt.Exec(context.Background(), string(typ), m.name, m.expected)
+				if err != nil {
+					return err
+				}
+				fixed += res.RowsAffected()
+			}
+			return nil
+		}, fmt.Sprintf(`WITH moved AS (DELETE FROM %s WHERE name = $2 RETURNING name, hash, created_at, source, submitter),
+			removed AS (INSERT INTO signature_removal (type, name, hash) SELECT $1, name, hash FROM moved)
+			INSERT INTO signature_quarantine (type, name, hash, expected_hash, created_at, source, submitter)
+			SELECT $1, name, hash, $3, created_at, source, submitter FROM moved
+			ON CONFLICT (type, name) DO UPDATE SET hash = excluded.hash, expected_hash = excluded.expected_hash, quarantined_at = now()`,
+			exportTables[typ]))
+	})
+
+	return fixed, err
+}
+
+// LatestAuditReport returns the report of the most recent audit, or nil if there hasn't been one
+func (d *Database) LatestAuditReport() (*client.AuditReport, error) {
+	var encoded []byte
+	if err := d.db.QueryRow(context.Background(), `SELECT report FROM audit_report ORDER BY id DESC LIMIT 1`).Scan(&encoded); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var report client.AuditReport
+	if err := json.Unmarshal(encoded, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
@@ -0,0 +1,106 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/jackc/pgx/v5"
+	"github.com/lib/pq"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/database"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/client"
+)
+
+func (d *Database) LoadCanonicalSignatures() ([]*client.CanonicalSignature, error) {
+	var result []*client.CanonicalSignature
+
+	if err := d.db.QuerySimple(func(rows pgx.Rows) error {
+		for rows.Next() {
+			var (
+				hash []byte
+				sig  client.CanonicalSignature
+				src  *string
+			)
+			if err := rows.Scan(&hash, &sig.Signature, &src, &sig.Manual, &sig.UpdatedAt); err != nil {
+				return fmt.Errorf("failed to scan: %w", err)
+			}
+			sig.Hash = hexutil.Encode(hash)
+			if src != nil {
+				sig.Source = *src
+			}
+			result = append(result, &sig)
+		}
+		return nil
+	}, `SELECT hash, signature, source, manual, updated_at FROM canonical_signature ORDER BY hash`); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// SaveCanonicalSignature adds or replaces a manual canonical signature
+func (d *Database) SaveCanonicalSignature(sig *client.CanonicalSignature) error {
+	hash, err := hexutil.Decode(sig.Hash)
+	if err != nil {
+		return err
+	}
+
+	_, err = d.db.Exec(context.Background(), `INSERT INTO canonical_signature (hash, signature, source, manual) VALUES ($1, $2, $3, true)
+		ON CONFLICT (hash) DO UPDATE SET signature = excluded.signature, source = excluded.source, manual = true, updated_at = now()`,
+		hash, sig.Signature, database.Nullable(sig.Source))
+	return err
+}
+
+func (d *Database) DeleteCanonicalSignature(sel string) (bool, error) {
+	hash, err := hexutil.Decode(sel)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := d.db.Exec(context.Background(), `DELETE FROM canonical_signature WHERE hash = $1`, hash)
+	if err != nil {
+		return false, err
+	}
+
+	return res.RowsAffected() > 0, nil
+}
+
+// SyncCanonicalSignatures upserts the upstream canonical signatures, leaving manual entries untouched. If prune is
+// set, upstream entries which are no longer present are removed, unless there are none at all.
+func (d *Database) SyncCanonicalSignatures(upstream []*client.CanonicalSignature, prune bool) error {
+	if prune && len(upstream) == 0 {
+		return fmt.Errorf("refusing to prune every upstream canonical signature")
+	}
+
+	return d.db.ExecTx(func(tx *database.Tx) error {
+		var hashes [][]byte
+
+		if err := tx.ExecBatch(func(stmt *database.Stmt) error {
+			for _, sig := range upstream {
+				hash, err := hexutil.Decode(sig.Hash)
+				if err != nil {
+					return fmt.Errorf("invalid hash %s: %w", sig.Hash, err)
+				}
+				hashes = append(hashes, hash)
+
+				if _, err := stmt.Exec(context.Background(), hash, sig.Signature, database.Nullable(sig.Source)); err != nil {
+					return fmt.Errorf("failed to upsert: %w", err)
+				}
+			}
+			return nil
+		}, `INSERT INTO canonical_signature (hash, signature, source) VALUES ($1, $2, $3)
+			ON CONFLICT (hash) DO UPDATE SET signature = excluded.signature, source = excluded.source, updated_at = now()
+			WHERE NOT canonical_signature.manual AND (canonical_signature.signature, canonical_signature.source) IS DISTINCT FROM (excluded.signature, excluded.source)`); err != nil {
+			return err
+		}
+
+		if !prune {
+			return nil
+		}
+
+		if _, err := tx.Exec(context.Background(), `DELETE FROM canonical_signature WHERE NOT manual AND NOT hash = ANY($1)`, pq.ByteaArray(hashes)); err != nil {
+			return fmt.Errorf("failed to prune: %w", err)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,168 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/jackc/pgx/v5"
+	"github.com/lib/pq"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/client"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FindCollidingHashes returns every hash of a type which more than one signature shares
+func (d *Database) FindCollidingHashes(typ client.SignatureType) ([]string, error) {
+	var hashes []string
+
+	if err := d.db.QuerySimple(func(r pgx.Rows) error {
+		var hash []byte
+		for r.Next() {
+			if err := r.Scan(&hash); err != nil {
+				return err
+			}
+			hashes = append(hashes, hexutil.Encode(hash))
+		}
+		return nil
+	}, fmt.Sprintf(`SELECT hash FROM %s GROUP BY hash HAVING COUNT(*) > 1`, exportTables[typ])); err != nil {
+		return nil, err
+	}
+
+	return hashes, nil
+}
+
+// SaveCollision records a collision, returning whether it's new or has changed since it was last saved, and the names
+// it had before, which are nil for new collisions. Names are stored as a sorted set, so a name being replaced by
+// another counts as a change
+func (d *Database) SaveCollision(c *client.Collision) (bool, []string, error) {
+	hash, err := hexutil.Decode(c.Hash)
+	if err != nil {
+		return false, nil, err
+	}
+
+	names := make([]string, 0, len(c.Names))
+	for _, name := range c.Names {
+		names = append(names, name.Name)
+	}
+	sort.Strings(names)
+
+	var previous []string
+	err = d.db.QueryRow(context.Background(), `WITH previous AS (SELECT names FROM collision WHERE type = $1 AND hash = $2)
+		INSERT INTO collision (type, hash, names, classification, reason) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (type, hash) DO UPDATE SET names = excluded.names, classification = excluded.classification, reason = excluded.reason, updated_at = now()
+		WHERE (collision.names, collision.classification) IS DISTINCT FROM (excluded.names, excluded.classification)
+		RETURNING detected_at, updated_at, (SELECT names FROM previous)`,
+		string(c.Type), hash, names, string(c.Classification), c.Reason,
+	).Scan(&c.DetectedAt, &c.UpdatedAt, &previous)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, names, nil
+	} else if err != nil {
+		return false, nil, err
+	}
+	return true, previous, nil
+}
+
+type CollisionFilter struct {
+	Type           client.SignatureType
+	Classification client.CollisionClassification
+	Hash           string
+	Since          time.Time
+}
+
+// QueryCollisions lists collisions, most recently updated first. Names aren't filled in
+func (d *Database) QueryCollisions(filter *CollisionFilter, limit int, offset int) ([]*client.Collision, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+	add := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Type != "" {
+		add("type = $%d", string(filter.Type))
+	}
+	if filter.Classification != "" {
+		add("classification = $%d", string(filter.Classification))
+	}
+	if filter.Hash != "" {
+		hash, err := hexutil.Decode(filter.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hash: %w", err)
+		}
+		add("hash = $%d", hash)
+	}
+	if !filter.Since.IsZero() {
+		add("updated_at > $%d", filter.Since)
+	}
+
+	query := `SELECT type, hash, classification, reason, detected_at, updated_at FROM collision`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, limit, offset)
+	query += fmt.Sprintf(" ORDER BY updated_at DESC, hash LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	collisions := []*client.Collision{}
+	if err := d.db.QuerySimple(func(r pgx.Rows) error {
+		for r.Next() {
+			c, err := scanCollision(r)
+			if err != nil {
+				return err
+			}
+			collisions = append(collisions, c)
+		}
+		return nil
+	}, query, args...); err != nil {
+		return nil, err
+	}
+
+	return collisions, nil
+}
+
+// LoadCollisions returns the collisions among the given hashes, keyed by hash. Names aren't filled in
+func (d *Database) LoadCollisions(typ client.SignatureType, hashes []string) (map[string]*client.Collision, error) {
+	var arr [][]byte
+	for _, hash := range hashes {
+		b, err := hexutil.Decode(hash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hash: %w", err)
+		}
+		arr = append(arr, b)
+	}
+
+	collisions := make(map[string]*client.Collision)
+	if err := d.db.QuerySimple(func(r pgx.Rows) error {
+		for r.Next() {
+			c, err := scanCollision(r)
+			if err != nil {
+				return err
+			}
+			collisions[c.Hash] = c
+		}
+		return nil
+	}, `SELECT type, hash, classification, reason, detected_at, updated_at FROM collision WHERE type = $1 AND hash = ANY($2)`, string(typ), pq.ByteaArray(arr)); err != nil {
+		return nil, err
+	}
+
+	return collisions, nil
+}
+
+func scanCollision(r pgx.Rows) (*client.Collision, error) {
+	var (
+		c      client.Collision
+		hash   []byte
+		reason *string
+	)
+	if err := r.Scan(&c.Type, &hash, &c.Classification, &reason, &c.DetectedAt, &c.UpdatedAt); err != nil {
+		return nil, err
+	}
+	c.Hash = hexutil.Encode(hash)
+	if reason != nil {
+		c.Reason = *reason
+	}
+	return &c, nil
+}
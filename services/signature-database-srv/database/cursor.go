@@ -0,0 +1,54 @@
+package database
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/client"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// searchPosition is the sort key of the last row returned for a signature type
+type searchPosition struct {
+	Rank   int    `json:"r"`
+	Length int    `json:"l"`
+	Name   string `json:"n"`
+	Done   bool   `json:"d,omitempty"`
+}
+
+// searchCursor is handed to clients as an opaque string so they can resume a search where the last page ended
+type searchCursor struct {
+	Query     string                                   `json:"q"`
+	Positions map[client.SignatureType]*searchPosition `json:"p"`
+}
+
+func (c *searchCursor) encode() string {
+	b, err := json.Marshal(c)
+	if err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeSearchCursor(query string, cursor string) (*searchCursor, error) {
+	if cursor == "" {
+		return &searchCursor{Query: query}, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var result searchCursor
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if result.Query != query {
+		return nil, ErrInvalidCursor
+	}
+
+	return &result, nil
+}
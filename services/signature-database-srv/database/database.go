@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"encoding/hex"
 	"fmt"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -10,46 +11,60 @@ import (
 	"github.com/lib/pq"
 	"github.com/openchainxyz/openchainxyz-monorepo/internal/database"
 	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/client"
-	"io"
-	"regexp"
-	"strings"
+	"time"
 )
 
 var signatureLens = map[client.SignatureType]int{
 	client.SignatureTypeFunction: 4,
 	client.SignatureTypeEvent:    32,
+	client.SignatureTypeError:    4,
 }
 
 var saveSignatureQueries = map[client.SignatureType]string{
-	client.SignatureTypeFunction: `INSERT INTO fourbyte (name, hash) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
-	client.SignatureTypeEvent:    `INSERT INTO thirtytwobyte (name, hash) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+	client.SignatureTypeFunction: `INSERT INTO fourbyte (name, hash, source, submitter) VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING`,
+	client.SignatureTypeEvent:    `INSERT INTO thirtytwobyte (name, hash, source, submitter) VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING`,
+	client.SignatureTypeError:    `INSERT INTO fourbyte_error (name, hash, source, submitter) VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING`,
 }
 
 var loadSignatureQueries = map[client.SignatureType]string{
-	client.SignatureTypeFunction: `SELECT name, hash FROM fourbyte where hash = ANY($1)`,
-	client.SignatureTypeEvent:    `SELECT name, hash FROM thirtytwobyte where hash = ANY($1)`,
+	client.SignatureTypeFunction: `SELECT name, hash, score, created_at, source, submitter FROM fourbyte where hash = ANY($1) ORDER BY score DESC, created_at NULLS FIRST, name`,
+	client.SignatureTypeEvent:    `SELECT name, hash, score, created_at, source, submitter FROM thirtytwobyte where hash = ANY($1) ORDER BY score DESC, created_at NULLS FIRST, name`,
+	client.SignatureTypeError:    `SELECT name, hash, score, created_at, source, submitter FROM fourbyte_error where hash = ANY($1) ORDER BY score DESC, created_at NULLS FIRST, name`,
 }
 
-var querySignatureQueries = map[client.SignatureType]string{
-	client.SignatureTypeFunction: `SELECT name, hash FROM fourbyte WHERE name LIKE $1 LIMIT $2`,
-	client.SignatureTypeEvent:    `SELECT name, hash FROM thirtytwobyte WHERE name LIKE $1 LIMIT $2`,
+// scoreSignatureQueries count each submitter's observation of a signature once, no matter how often it's reported
+var scoreSignatureQueries = map[client.SignatureType]string{
+	client.SignatureTypeFunction: `WITH observed AS (INSERT INTO signature_observation (type, name, submitter) SELECT DISTINCT 'function', unnest($1::varchar[]), $2 ON CONFLICT DO NOTHING RETURNING name) UPDATE fourbyte SET score = score + 1 WHERE name IN (SELECT name FROM observed)`,
+	client.SignatureTypeEvent:    `WITH observed AS (INSERT INTO signature_observation (type, name, submitter) SELECT DISTINCT 'event', unnest($1::varchar[]), $2 ON CONFLICT DO NOTHING RETURNING name) UPDATE thirtytwobyte SET score = score + 1 WHERE name IN (SELECT name FROM observed)`,
+	client.SignatureTypeError:    `WITH observed AS (INSERT INTO signature_observation (type, name, submitter) SELECT DISTINCT 'error', unnest($1::varchar[]), $2 ON CONFLICT DO NOTHING RETURNING name) UPDATE fourbyte_error SET score = score + 1 WHERE name IN (SELECT name FROM observed)`,
 }
 
 var countSignatureQueries = map[client.SignatureType]string{
 	client.SignatureTypeFunction: `SELECT COUNT(*) FROM fourbyte`,
 	client.SignatureTypeEvent:    `SELECT COUNT(*) FROM thirtytwobyte`,
+	client.SignatureTypeError:    `SELECT COUNT(*) FROM fourbyte_error`,
 }
 
-func (d *Database) SaveSignatures(typ client.SignatureType, names []string) (*client.ImportResponseDetails, error) {
+// SignatureMetadata is recorded alongside newly imported signatures
+type SignatureMetadata struct {
+	Source    client.SignatureSource
+	Submitter string
+
+	// Observed raises the score of every signature by one, whether it was just imported or already existed, unless the
+	// submitter has observed it before. Anonymous observations don't count
+	Observed bool
+}
+
+func (d *Database) SaveSignatures(typ client.SignatureType, names []string, meta *SignatureMetadata) (*client.ImportResponseDetails, error) {
 	result := client.NewImportResponseDetails()
 
 	if err := d.db.ExecTx(func(tx *database.Tx) error {
-		return tx.ExecBatch(func(stmt *database.Stmt) error {
+		if err := tx.ExecBatch(func(stmt *database.Stmt) error {
 			for _, name := range names {
 				sig := crypto.Keccak256([]byte(name))[:signatureLens[typ]]
 				hexSig := "0x" + hex.EncodeToString(sig)
 
-				res, err := stmt.Exec(context.Background(), name, sig)
+				res, err := stmt.Exec(context.Background(), name, sig, database.Nullable(string(meta.Source)), database.Nullable(meta.Submitter))
 				if err != nil {
 					return fmt.Errorf("failed to insert: %w", err)
 				}
@@ -63,98 +78,16 @@ func (d *Database) SaveSignatures(typ client.SignatureType, names []string) (*cl
 			}
 
 			return nil
-		}, saveSignatureQueries[typ])
-	}); err != nil {
-		return nil, err
-	}
-
-	return result, nil
-}
-
-func (d *Database) ExportData(w io.Writer) error {
-	if err := d.db.QuerySimple(func(r pgx.Rows) error {
-		var (
-			name string
-			hash []byte
-		)
-		for r.Next() {
-			if err := r.Scan(&name, &hash); err != nil {
-				return err
-			}
-			if _, err := io.WriteString(w, fmt.Sprintf("0x%x,%s\n", hash, name)); err != nil {
-				return err
-			}
+		}, saveSignatureQueries[typ]); err != nil {
+			return err
 		}
-		return nil
-	}, `SELECT * FROM fourbyte ORDER BY hash`); err != nil {
-		return err
-	}
-	if err := d.db.QuerySimple(func(r pgx.Rows) error {
-		var (
-			name string
-			hash []byte
-		)
-		for r.Next() {
-			if err := r.Scan(&name, &hash); err != nil {
-				return err
-			}
-			if _, err := io.WriteString(w, fmt.Sprintf("0x%x,%s\n", hash, name)); err != nil {
-				return err
-			}
-		}
-		return nil
-	}, `SELECT * FROM thirtytwobyte ORDER BY hash`); err != nil {
-		return err
-	}
-
-	return nil
-}
 
-var isValidQuery = regexp.MustCompile(`^[a-zA-Z0-9$_()\[\],*?]+$`).MatchString
-
-func (d *Database) sanitizeQuery(name string) (string, error) {
-	if !isValidQuery(name) {
-		return "", fmt.Errorf("invalid query: %s", name)
-	}
-
-	name = strings.ReplaceAll(name, "_", "\\_")
-	name = strings.ReplaceAll(name, "*", "%")
-	name = strings.ReplaceAll(name, "?", "_")
-	return name, nil
-}
-
-func (d *Database) QuerySignatures(query string) (map[client.SignatureType]map[string][]*client.SignatureData, error) {
-	sanitizedQuery, err := d.sanitizeQuery(query)
-	if err != nil {
-		return nil, err
-	}
-
-	result := make(map[client.SignatureType]map[string][]*client.SignatureData)
-
-	if err := d.db.ExecTx(func(tx *database.Tx) error {
-		for _, typ := range client.SignatureTypes() {
-			result[typ] = make(map[string][]*client.SignatureData)
-
-			if err := tx.QuerySimple(func(r pgx.Rows) error {
-				for r.Next() {
-					var (
-						name string
-						hash []byte
-					)
-					if err := r.Scan(&name, &hash); err != nil {
-						return fmt.Errorf("failed to scan: %w", err)
-					}
-
-					sel := "0x" + hex.EncodeToString(hash)
-					result[typ][sel] = append(result[typ][sel], &client.SignatureData{
-						Name: name,
-					})
-				}
+		if !meta.Observed || meta.Submitter == "" || len(names) == 0 {
+			return nil
+		}
 
-				return nil
-			}, querySignatureQueries[typ], sanitizedQuery, 100); err != nil {
-				return err
-			}
+		if _, err := tx.Exec(context.Background(), scoreSignatureQueries[typ], names, meta.Submitter); err != nil {
+			return fmt.Errorf("failed to update scores: %w", err)
 		}
 
 		return nil
@@ -180,17 +113,25 @@ func (d *Database) LoadSignatures(typ client.SignatureType, sels []string) (map[
 	if err := d.db.QuerySimple(func(rows pgx.Rows) error {
 		for rows.Next() {
 			var (
-				name string
-				sel  []byte
+				name      string
+				sel       []byte
+				score     int64
+				createdAt *time.Time
+				source    sql.NullString
+				submitter sql.NullString
 			)
-			if err := rows.Scan(&name, &sel); err != nil {
+			if err := rows.Scan(&name, &sel, &score, &createdAt, &source, &submitter); err != nil {
 				return fmt.Errorf("failed to scan: %w", err)
 			}
 
 			h := hexutil.Encode(sel)
 
 			result[h] = append(result[h], &client.SignatureData{
-				Name: name,
+				Name:      name,
+				Score:     score,
+				CreatedAt: createdAt,
+				Source:    client.SignatureSource(source.String),
+				Submitter: submitter.String,
 			})
 		}
 		return nil
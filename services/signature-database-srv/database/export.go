@@ -0,0 +1,144 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/database"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/client"
+	"time"
+)
+
+// exportLockTimeout bounds how long ExportVersion waits for the imports in progress to finish
+const exportLockTimeout = 10 * time.Second
+
+// ErrImportsInProgress is returned by ExportVersion when it isn't allowed to wait for the imports in progress
+var ErrImportsInProgress = errors.New("imports in progress")
+
+var exportTables = map[client.SignatureType]string{
+	client.SignatureTypeFunction: "fourbyte",
+	client.SignatureTypeEvent:    "thirtytwobyte",
+	client.SignatureTypeError:    "fourbyte_error",
+}
+
+// ExportVersion returns the highest id which is safe to export. Ids are handed out when rows are inserted but only
+// become visible on commit, so they're assigned under a shared lock which the inserting transaction holds until it
+// ends. Taking the lock exclusively waits until every id handed out so far has been committed or rolled back, and holds
+// up every import which starts meanwhile, so unless wait is set it returns ErrImportsInProgress instead of waiting
+func (d *Database) ExportVersion(wait bool) (int64, error) {
+	var version int64
+	if err := d.db.ExecTx(func(tx *database.Tx) error {
+		if wait {
+			if _, err := tx.Exec(context.Background(), `SELECT set_config('lock_timeout', $1, true)`, fmt.Sprintf("%dms", exportLockTimeout.Milliseconds())); err != nil {
+				return err
+			}
+			if _, err := tx.Exec(context.Background(), `SELECT pg_advisory_xact_lock('signature_id_seq'::regclass::oid::bigint)`); err != nil {
+				return fmt.Errorf("failed to wait for imports in progress: %w", err)
+			}
+		} else {
+			var locked bool
+			if err := tx.QueryRowSimple(func(row pgx.Row) error {
+				return row.Scan(&locked)
+			}, `SELECT pg_try_advisory_xact_lock('signature_id_seq'::regclass::oid::bigint)`); err != nil {
+				return err
+			}
+			if !locked {
+				return ErrImportsInProgress
+			}
+		}
+
+		tables := []string{"signature_removal"}
+		for _, typ := range client.SignatureTypes() {
+			tables = append(tables, exportTables[typ])
+		}
+		for _, table := range tables {
+			var max int64
+			if err := tx.QueryRowSimple(func(row pgx.Row) error {
+				return row.Scan(&max)
+			}, fmt.Sprintf(`SELECT COALESCE(MAX(id), 0) FROM %s`, table)); err != nil {
+				return err
+			}
+			if max > version {
+				version = max
+			}
+		}
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// RemoveSignature deletes a signature if it has the given hash, recording the removal for incremental exports
+func (d *Database) RemoveSignature(typ client.SignatureType, name string, hash []byte) (bool, error) {
+	table, ok := exportTables[typ]
+	if !ok {
+		return false, fmt.Errorf("unknown signature type: %s", typ)
+	}
+
+	res, err := d.db.Exec(context.Background(), fmt.Sprintf(`WITH deleted AS (DELETE FROM %s WHERE name = $2 AND hash = $3 RETURNING name, hash)
+		INSERT INTO signature_removal (type, name, hash) SELECT $1, name, hash FROM deleted`, table), string(typ), name, hash)
+	if err != nil {
+		return false, err
+	}
+	return res.RowsAffected() > 0, nil
+}
+
+// ExportSince selects which signatures an incremental export includes
+type ExportSince struct {
+	ID   int64
+	Time time.Time
+}
+
+// ExportSignatures streams the signatures of a type with ids up to version, ordered by hash
+func (d *Database) ExportSignatures(typ client.SignatureType, version int64, apply func(*client.ExportedSignature) error) error {
+	table, ok := exportTables[typ]
+	if !ok {
+		return fmt.Errorf("unknown signature type: %s", typ)
+	}
+
+	return d.queryExport(typ, apply, fmt.Sprintf(`SELECT id, name, hash, false FROM %s WHERE id <= $1 ORDER BY hash`, table), version)
+}
+
+// ExportChanges streams the signatures of a type added or removed since the given id or time, up to version, ordered
+// by id. Removed signatures are marked as such
+func (d *Database) ExportChanges(typ client.SignatureType, version int64, since ExportSince, apply func(*client.ExportedSignature) error) error {
+	table, ok := exportTables[typ]
+	if !ok {
+		return fmt.Errorf("unknown signature type: %s", typ)
+	}
+
+	if since.Time.IsZero() {
+		return d.queryExport(typ, apply, fmt.Sprintf(`SELECT id, name, hash, false FROM %s WHERE id <= $1 AND id > $2
+			UNION ALL SELECT id, name, hash, true FROM signature_removal WHERE type = $3 AND id <= $1 AND id > $2 ORDER BY id`, table), version, since.ID, string(typ))
+	}
+	return d.queryExport(typ, apply, fmt.Sprintf(`SELECT id, name, hash, false FROM %s WHERE id <= $1 AND id > $2 AND created_at > $4
+		UNION ALL SELECT id, name, hash, true FROM signature_removal WHERE type = $3 AND id <= $1 AND id > $2 AND removed_at > $4 ORDER BY id`, table), version, since.ID, string(typ), since.Time)
+}
+
+func (d *Database) queryExport(typ client.SignatureType, apply func(*client.ExportedSignature) error, query string, args ...any) error {
+	return d.db.QuerySimple(func(r pgx.Rows) error {
+		var (
+			id      int64
+			name    string
+			hash    []byte
+			removed bool
+		)
+		for r.Next() {
+			if err := r.Scan(&id, &name, &hash, &removed); err != nil {
+				return err
+			}
+			if err := apply(&client.ExportedSignature{
+				ID:      id,
+				Type:    typ,
+				Hash:    fmt.Sprintf("0x%x", hash),
+				Name:    name,
+				Removed: removed,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, query, args...)
+}
@@ -1,8 +1,12 @@
 package database
 
 import (
+	"context"
 	"embed"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/auth"
 	"github.com/openchainxyz/openchainxyz-monorepo/internal/database"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/ratelimit"
 )
 
 //go:embed migrations
@@ -27,3 +31,19 @@ func New(host string, port int, dbname string, user string, pass string) (*Datab
 func NewWithDatabase(db *database.Database) *Database {
 	return &Database{db: db}
 }
+
+func (d *Database) KeyStore(encryptionKey []byte) (*auth.DatabaseKeyStore, error) {
+	return auth.NewDatabaseKeyStore(d.db, encryptionKey)
+}
+
+func (d *Database) RateLimiter() *ratelimit.DatabaseLimiter {
+	return ratelimit.NewDatabaseLimiter(d.db)
+}
+
+func (d *Database) Ping(ctx context.Context) error {
+	return d.db.Ping(ctx)
+}
+
+func (d *Database) Stat() *pgxpool.Stat {
+	return d.db.Stat()
+}
@@ -0,0 +1 @@
+DROP TABLE fourbyte_error;
\ No newline at end of file
@@ -0,0 +1,7 @@
+CREATE TABLE fourbyte_error
+(
+    name varchar PRIMARY KEY,
+    hash bytea
+);
+
+CREATE INDEX IF NOT EXISTS fourbyte_error_hash ON fourbyte_error USING btree (hash);
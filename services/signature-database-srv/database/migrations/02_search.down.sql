@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS fourbyte_error_name_trgm;
+DROP INDEX fourbyte_error_args;
+
+DROP INDEX IF EXISTS thirtytwobyte_name_trgm;
+DROP INDEX thirtytwobyte_args;
+
+DROP INDEX IF EXISTS fourbyte_name_trgm;
+DROP INDEX fourbyte_args;
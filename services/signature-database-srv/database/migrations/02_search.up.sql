@@ -0,0 +1,26 @@
+-- pg_trgm only indexes the case-insensitive name search, so carry on without it if the app role can't create it. A
+-- superuser can run CREATE EXTENSION pg_trgm and re-create the *_name_trgm indexes below later on.
+DO $$
+BEGIN
+    CREATE EXTENSION IF NOT EXISTS pg_trgm;
+EXCEPTION WHEN insufficient_privilege THEN
+    RAISE WARNING 'could not create pg_trgm, name searches will not be indexed';
+END
+$$;
+
+-- the arguments are indexed by expression rather than stored in a generated column, which would rewrite each table under
+-- an exclusive lock. The expression must match argsExpression in search.go exactly for the index to be used, and names
+-- without arguments are left out rather than indexed whole
+CREATE INDEX IF NOT EXISTS fourbyte_args ON fourbyte USING btree ((CASE WHEN strpos(name, '(') > 0 THEN substr(name, strpos(name, '(')) END));
+CREATE INDEX IF NOT EXISTS thirtytwobyte_args ON thirtytwobyte USING btree ((CASE WHEN strpos(name, '(') > 0 THEN substr(name, strpos(name, '(')) END));
+CREATE INDEX IF NOT EXISTS fourbyte_error_args ON fourbyte_error USING btree ((CASE WHEN strpos(name, '(') > 0 THEN substr(name, strpos(name, '(')) END));
+
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pg_trgm') THEN
+        CREATE INDEX IF NOT EXISTS fourbyte_name_trgm ON fourbyte USING gin (name gin_trgm_ops);
+        CREATE INDEX IF NOT EXISTS thirtytwobyte_name_trgm ON thirtytwobyte USING gin (name gin_trgm_ops);
+        CREATE INDEX IF NOT EXISTS fourbyte_error_name_trgm ON fourbyte_error USING gin (name gin_trgm_ops);
+    END IF;
+END
+$$;
@@ -0,0 +1,11 @@
+ALTER TABLE fourbyte_error DROP COLUMN submitter;
+ALTER TABLE fourbyte_error DROP COLUMN source;
+ALTER TABLE fourbyte_error DROP COLUMN created_at;
+
+ALTER TABLE thirtytwobyte DROP COLUMN submitter;
+ALTER TABLE thirtytwobyte DROP COLUMN source;
+ALTER TABLE thirtytwobyte DROP COLUMN created_at;
+
+ALTER TABLE fourbyte DROP COLUMN submitter;
+ALTER TABLE fourbyte DROP COLUMN source;
+ALTER TABLE fourbyte DROP COLUMN created_at;
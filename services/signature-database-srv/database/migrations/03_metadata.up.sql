@@ -0,0 +1,15 @@
+-- signatures imported before this migration have no known metadata, so leave them null
+ALTER TABLE fourbyte ADD COLUMN created_at timestamptz;
+ALTER TABLE fourbyte ALTER COLUMN created_at SET DEFAULT now();
+ALTER TABLE fourbyte ADD COLUMN source varchar;
+ALTER TABLE fourbyte ADD COLUMN submitter varchar;
+
+ALTER TABLE thirtytwobyte ADD COLUMN created_at timestamptz;
+ALTER TABLE thirtytwobyte ALTER COLUMN created_at SET DEFAULT now();
+ALTER TABLE thirtytwobyte ADD COLUMN source varchar;
+ALTER TABLE thirtytwobyte ADD COLUMN submitter varchar;
+
+ALTER TABLE fourbyte_error ADD COLUMN created_at timestamptz;
+ALTER TABLE fourbyte_error ALTER COLUMN created_at SET DEFAULT now();
+ALTER TABLE fourbyte_error ADD COLUMN source varchar;
+ALTER TABLE fourbyte_error ADD COLUMN submitter varchar;
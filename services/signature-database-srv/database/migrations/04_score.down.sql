@@ -0,0 +1,3 @@
+ALTER TABLE fourbyte_error DROP COLUMN score;
+ALTER TABLE thirtytwobyte DROP COLUMN score;
+ALTER TABLE fourbyte DROP COLUMN score;
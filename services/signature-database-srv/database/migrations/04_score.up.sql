@@ -0,0 +1,3 @@
+ALTER TABLE fourbyte ADD COLUMN score bigint NOT NULL DEFAULT 0;
+ALTER TABLE thirtytwobyte ADD COLUMN score bigint NOT NULL DEFAULT 0;
+ALTER TABLE fourbyte_error ADD COLUMN score bigint NOT NULL DEFAULT 0;
@@ -0,0 +1 @@
+DROP TABLE canonical_signature;
\ No newline at end of file
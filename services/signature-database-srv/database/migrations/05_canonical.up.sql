@@ -0,0 +1,9 @@
+CREATE TABLE canonical_signature
+(
+    hash       bytea PRIMARY KEY,
+    signature  varchar     NOT NULL,
+    source     varchar,
+    -- manual entries were added through the api and take precedence over the upstream list
+    manual     boolean     NOT NULL DEFAULT false,
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
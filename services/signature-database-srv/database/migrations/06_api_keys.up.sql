@@ -0,0 +1,9 @@
+CREATE TABLE api_key
+(
+    id          varchar PRIMARY KEY,
+    name        varchar     NOT NULL,
+    role        varchar     NOT NULL,
+    secret_hash bytea       NOT NULL,
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    revoked_at  timestamptz
+);
@@ -0,0 +1,5 @@
+CREATE UNLOGGED TABLE rate_limit_bucket
+(
+    key varchar PRIMARY KEY,
+    tat timestamptz NOT NULL
+);
\ No newline at end of file
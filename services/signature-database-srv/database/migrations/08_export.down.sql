@@ -0,0 +1,5 @@
+ALTER TABLE fourbyte DROP COLUMN id;
+ALTER TABLE thirtytwobyte DROP COLUMN id;
+ALTER TABLE fourbyte_error DROP COLUMN id;
+
+DROP SEQUENCE signature_id_seq;
@@ -0,0 +1,14 @@
+-- a single sequence across every table lets mirrors follow all of them with one high-water mark
+CREATE SEQUENCE signature_id_seq;
+
+ALTER TABLE fourbyte ADD COLUMN id bigint NOT NULL DEFAULT nextval('signature_id_seq');
+ALTER TABLE thirtytwobyte ADD COLUMN id bigint NOT NULL DEFAULT nextval('signature_id_seq');
+ALTER TABLE fourbyte_error ADD COLUMN id bigint NOT NULL DEFAULT nextval('signature_id_seq');
+
+CREATE UNIQUE INDEX IF NOT EXISTS fourbyte_id ON fourbyte USING btree (id);
+CREATE UNIQUE INDEX IF NOT EXISTS thirtytwobyte_id ON thirtytwobyte USING btree (id);
+CREATE UNIQUE INDEX IF NOT EXISTS fourbyte_error_id ON fourbyte_error USING btree (id);
+
+CREATE INDEX IF NOT EXISTS fourbyte_created_at ON fourbyte USING btree (created_at);
+CREATE INDEX IF NOT EXISTS thirtytwobyte_created_at ON thirtytwobyte USING btree (created_at);
+CREATE INDEX IF NOT EXISTS fourbyte_error_created_at ON fourbyte_error USING btree (created_at);
@@ -0,0 +1,8 @@
+CREATE TABLE sync_state
+(
+    upstream   varchar     NOT NULL,
+    type       varchar     NOT NULL,
+    last_id    bigint      NOT NULL,
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (upstream, type)
+);
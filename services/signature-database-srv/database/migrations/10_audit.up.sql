@@ -0,0 +1,21 @@
+CREATE TABLE signature_quarantine
+(
+    type           varchar     NOT NULL,
+    name           varchar     NOT NULL,
+    hash           bytea,
+    expected_hash  bytea       NOT NULL,
+    created_at     timestamptz,
+    source         varchar,
+    submitter      varchar,
+    quarantined_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (type, name)
+);
+
+CREATE TABLE audit_report
+(
+    id          bigserial PRIMARY KEY,
+    mode        varchar     NOT NULL,
+    started_at  timestamptz NOT NULL,
+    finished_at timestamptz NOT NULL,
+    report      jsonb       NOT NULL
+);
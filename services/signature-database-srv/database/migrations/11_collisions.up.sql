@@ -0,0 +1,13 @@
+CREATE TABLE collision
+(
+    type           varchar     NOT NULL,
+    hash           bytea       NOT NULL,
+    names          varchar[]   NOT NULL,
+    classification varchar     NOT NULL,
+    reason         varchar,
+    detected_at    timestamptz NOT NULL DEFAULT now(),
+    updated_at     timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (type, hash)
+);
+
+CREATE INDEX IF NOT EXISTS collision_updated_at ON collision USING btree (updated_at);
@@ -0,0 +1,7 @@
+CREATE TABLE signature_observation
+(
+    type      varchar NOT NULL,
+    name      varchar NOT NULL,
+    submitter varchar NOT NULL,
+    PRIMARY KEY (type, name, submitter)
+);
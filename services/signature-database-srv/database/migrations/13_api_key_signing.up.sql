@@ -0,0 +1,4 @@
+-- keys created before this can only be presented as bearer tokens, as their signing keys can't be derived from the
+-- stored hashes
+ALTER TABLE api_key
+    ADD COLUMN signing_key bytea;
\ No newline at end of file
@@ -0,0 +1,9 @@
+DROP TRIGGER fourbyte_assign_id ON fourbyte;
+DROP TRIGGER thirtytwobyte_assign_id ON thirtytwobyte;
+DROP TRIGGER fourbyte_error_assign_id ON fourbyte_error;
+
+ALTER TABLE fourbyte ALTER COLUMN id SET DEFAULT nextval('signature_id_seq');
+ALTER TABLE thirtytwobyte ALTER COLUMN id SET DEFAULT nextval('signature_id_seq');
+ALTER TABLE fourbyte_error ALTER COLUMN id SET DEFAULT nextval('signature_id_seq');
+
+DROP FUNCTION assign_signature_id();
\ No newline at end of file
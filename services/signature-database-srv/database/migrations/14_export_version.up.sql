@@ -0,0 +1,19 @@
+-- ids are handed out under a shared lock held until the inserting transaction ends, so that taking the lock
+-- exclusively waits until every id handed out so far is either committed or rolled back. the lock is keyed by the
+-- sequence's oid. ids are assigned by the trigger rather than a default, as defaults are evaluated before triggers run
+CREATE FUNCTION assign_signature_id() RETURNS trigger AS
+$$
+BEGIN
+    PERFORM pg_advisory_xact_lock_shared('signature_id_seq'::regclass::oid::bigint);
+    NEW.id := nextval('signature_id_seq');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE fourbyte ALTER COLUMN id DROP DEFAULT;
+ALTER TABLE thirtytwobyte ALTER COLUMN id DROP DEFAULT;
+ALTER TABLE fourbyte_error ALTER COLUMN id DROP DEFAULT;
+
+CREATE TRIGGER fourbyte_assign_id BEFORE INSERT ON fourbyte FOR EACH ROW EXECUTE FUNCTION assign_signature_id();
+CREATE TRIGGER thirtytwobyte_assign_id BEFORE INSERT ON thirtytwobyte FOR EACH ROW EXECUTE FUNCTION assign_signature_id();
+CREATE TRIGGER fourbyte_error_assign_id BEFORE INSERT ON fourbyte_error FOR EACH ROW EXECUTE FUNCTION assign_signature_id();
\ No newline at end of file
@@ -0,0 +1,15 @@
+-- signatures removed by an audit, so that incremental exports can tell mirrors to drop them. a repair removes the row
+-- with its old hash and re-adds it with a new id. ids come from the same sequence as the signatures, so removals are
+-- ordered alongside the additions they undo
+CREATE TABLE signature_removal
+(
+    id         bigint PRIMARY KEY,
+    type       varchar     NOT NULL,
+    name       varchar     NOT NULL,
+    hash       bytea       NOT NULL,
+    removed_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX signature_removal_type_id ON signature_removal (type, id);
+
+CREATE TRIGGER signature_removal_assign_id BEFORE INSERT ON signature_removal FOR EACH ROW EXECUTE FUNCTION assign_signature_id();
@@ -0,0 +1,227 @@
+package database
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/database"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/solidity"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/client"
+	"regexp"
+	"strings"
+)
+
+var searchTables = map[client.SignatureType]string{
+	client.SignatureTypeFunction: "fourbyte",
+	client.SignatureTypeEvent:    "thirtytwobyte",
+	client.SignatureTypeError:    "fourbyte_error",
+}
+
+// argsExpression is the argument list of a signature, as indexed by the *_args indexes. It must stay the same as the
+// index expression in the 02_search migration or searches by args will scan the whole table
+const argsExpression = `(CASE WHEN strpos(name, '(') > 0 THEN substr(name, strpos(name, '(')) END)`
+
+// $1 is the exact match, $2-$4 are the position to resume after, and $5 is the limit. Filter conditions start at $6.
+const querySignaturesTemplate = `SELECT name, hash, rank, length(name) FROM (
+	SELECT name, hash, CASE
+		WHEN name = $1 OR split_part(name, '(', 1) = $1 THEN 0
+		WHEN lower(split_part(name, '(', 1)) = lower($1) THEN 1
+		ELSE 2
+	END AS rank FROM %s WHERE %s
+) AS matches WHERE (rank, length(name), name) > ($2, $3, $4) ORDER BY rank, length(name), name LIMIT $5`
+
+// SearchFilter describes which signatures to search for. Every non-empty field must match.
+type SearchFilter struct {
+	// Query is matched against the full signature, with '*' and '?' as wildcards
+	Query string
+	// Name is a case-insensitive substring of the name, excluding arguments
+	Name string
+	// Args is the exact argument list, like '(address,uint256)'
+	Args string
+	// Param is the type of any argument, like 'address' or '(uint256,bool)[]'. Signatures don't record which event
+	// arguments are indexed, so there's no way to search for those
+	Param string
+	// Type restricts the search to a single signature type
+	Type client.SignatureType
+}
+
+func (f *SearchFilter) types() []client.SignatureType {
+	if f.Type != "" {
+		return []client.SignatureType{f.Type}
+	}
+	return client.SignatureTypes()
+}
+
+// key uniquely identifies the filter, so that cursors can't be reused across searches
+func (f *SearchFilter) key() string {
+	return strings.Join([]string{f.Query, f.Name, f.Args, f.Param, string(f.Type)}, "|")
+}
+
+// exact returns the text which, if equal to a result's signature or name, ranks that result first
+func (f *SearchFilter) exact() string {
+	if f.Query != "" {
+		return strings.NewReplacer("*", "", "?", "").Replace(f.Query)
+	}
+	return f.Name
+}
+
+var ErrInvalidSearch = errors.New("invalid search")
+
+var (
+	isValidQuery = regexp.MustCompile(`^[a-zA-Z0-9$_()\[\],*?]+$`).MatchString
+	isValidName  = regexp.MustCompile(`^[a-zA-Z0-9$_]+$`).MatchString
+)
+
+func sanitizeQuery(name string) (string, error) {
+	if !isValidQuery(name) {
+		return "", fmt.Errorf("%w: invalid query %s", ErrInvalidSearch, name)
+	}
+
+	name = strings.ReplaceAll(name, "_", "\\_")
+	name = strings.ReplaceAll(name, "*", "%")
+	name = strings.ReplaceAll(name, "?", "_")
+	return name, nil
+}
+
+// conditions returns the sql conditions for the filter, numbering parameters from offset
+func (f *SearchFilter) conditions(offset int) ([]string, []any, error) {
+	var (
+		conds []string
+		args  []any
+	)
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, offset+len(args)-1))
+	}
+
+	if f.Type != "" && !f.Type.Valid() {
+		return nil, nil, fmt.Errorf("%w: invalid type %s", ErrInvalidSearch, f.Type)
+	}
+
+	if f.Query != "" {
+		sanitizedQuery, err := sanitizeQuery(f.Query)
+		if err != nil {
+			return nil, nil, err
+		}
+		add("name LIKE $%d", sanitizedQuery)
+	}
+
+	if f.Name != "" {
+		if !isValidName(f.Name) {
+			return nil, nil, fmt.Errorf("%w: invalid name %s", ErrInvalidSearch, f.Name)
+		}
+		// the first condition can use the trigram index, the second makes sure we didn't match an argument
+		pattern := "%" + strings.ReplaceAll(f.Name, "_", "\\_") + "%"
+		add("name ILIKE $%d", pattern)
+		add("split_part(name, '(', 1) ILIKE $%d", pattern)
+	}
+
+	if f.Args != "" {
+		if !solidity.VerifySignature("a" + f.Args) {
+			return nil, nil, fmt.Errorf("%w: invalid args %s", ErrInvalidSearch, f.Args)
+		}
+		add(argsExpression+" = $%d", f.Args)
+	}
+
+	if f.Param != "" {
+		if !solidity.VerifySignature("a(" + f.Param + ")") {
+			return nil, nil, fmt.Errorf("%w: invalid param %s", ErrInvalidSearch, f.Param)
+		}
+		// a parameter is always preceded by an open bracket or comma, and followed by a close bracket or comma
+		add("name ~ $%d", "[(,]"+regexp.QuoteMeta(f.Param)+"[,)]")
+	}
+
+	if len(conds) == 0 {
+		return nil, nil, fmt.Errorf("%w: no filters given", ErrInvalidSearch)
+	}
+
+	return conds, args, nil
+}
+
+// QuerySignatures returns up to limit signatures of each type which match the filter. Exact matches on the full
+// signature or the bare name are ranked first, followed by shorter names, with ties broken alphabetically.
+// An empty cursor starts from the beginning, and the returned cursor is empty once every type has been exhausted.
+func (d *Database) QuerySignatures(filter *SearchFilter, cursor string, limit int) (*client.SearchResponse, error) {
+	conds, condArgs, err := filter.conditions(6)
+	if err != nil {
+		return nil, err
+	}
+
+	position, err := decodeSearchCursor(filter.key(), cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	exact := filter.exact()
+
+	result := client.NewSearchResponse()
+	nextPosition := &searchCursor{
+		Query:     filter.key(),
+		Positions: make(map[client.SignatureType]*searchPosition),
+	}
+
+	if err := d.db.ExecTx(func(tx *database.Tx) error {
+		for _, typ := range filter.types() {
+			after := position.Positions[typ]
+			if after == nil {
+				after = &searchPosition{Rank: -1}
+			}
+			if after.Done {
+				nextPosition.Positions[typ] = after
+				continue
+			}
+
+			var last *searchPosition
+
+			query := fmt.Sprintf(querySignaturesTemplate, searchTables[typ], strings.Join(conds, " AND "))
+			args := append([]any{exact, after.Rank, after.Length, after.Name, limit + 1}, condArgs...)
+
+			if err := tx.QuerySimple(func(r pgx.Rows) error {
+				for r.Next() {
+					var (
+						name   string
+						hash   []byte
+						rank   int
+						length int
+					)
+					if err := r.Scan(&name, &hash, &rank, &length); err != nil {
+						return fmt.Errorf("failed to scan: %w", err)
+					}
+
+					if len(result.Results[typ]) == limit {
+						// there is at least one more row, so resume after the last one we returned
+						nextPosition.Positions[typ] = last
+						return nil
+					}
+
+					result.Results[typ] = append(result.Results[typ], &client.SearchResult{
+						Hash: "0x" + hex.EncodeToString(hash),
+						SignatureData: &client.SignatureData{
+							Name: name,
+						},
+					})
+					last = &searchPosition{Rank: rank, Length: length, Name: name}
+				}
+
+				nextPosition.Positions[typ] = &searchPosition{Done: true}
+				return nil
+			}, query, args...); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	for _, typ := range filter.types() {
+		if !nextPosition.Positions[typ].Done {
+			result.NextCursor = nextPosition.encode()
+			break
+		}
+	}
+
+	return result, nil
+}
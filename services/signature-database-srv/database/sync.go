@@ -0,0 +1,36 @@
+package database
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/client"
+)
+
+// LoadSyncState returns the id of the last signature of each type mirrored from an upstream
+func (d *Database) LoadSyncState(upstream string) (map[client.SignatureType]int64, error) {
+	state := make(map[client.SignatureType]int64)
+
+	if err := d.db.QuerySimple(func(r pgx.Rows) error {
+		var (
+			typ    string
+			lastID int64
+		)
+		for r.Next() {
+			if err := r.Scan(&typ, &lastID); err != nil {
+				return err
+			}
+			state[client.SignatureType(typ)] = lastID
+		}
+		return nil
+	}, `SELECT type, last_id FROM sync_state WHERE upstream = $1`, upstream); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+func (d *Database) SaveSyncState(upstream string, typ client.SignatureType, lastID int64) error {
+	_, err := d.db.Exec(context.Background(), `INSERT INTO sync_state (upstream, type, last_id) VALUES ($1, $2, $3)
+		ON CONFLICT (upstream, type) DO UPDATE SET last_id = excluded.last_id, updated_at = now()`, upstream, string(typ), lastID)
+	return err
+}
@@ -0,0 +1,180 @@
+package signature_database_srv
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/core"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/solidity"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/client"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+)
+
+var errInvalidDecodeRequest = errors.New("invalid decode request")
+
+// decodeWorkSize is how many bytes of data a single rate limit token pays for decoding against one candidate
+const decodeWorkSize = 4096
+
+type decoder func(sig string) ([]*solidity.DecodedValue, error)
+
+// decode trial-decodes calldata or a log with the known signatures for its selector or topic, keeping only the
+// signatures which decode cleanly. Selectors collide often enough that this is the only reliable way to tell which
+// signature was meant. Only the best scored candidates are tried, and once it's known how many, charge is given the
+// work it'll take so that it can refuse before any of it is done
+func (s *Service) decode(req *client.DecodeRequest, shouldFilter bool, charge func(work int) error) (*client.DecodeResponse, error) {
+	decoders := make(map[client.SignatureType]decoder)
+	var hash string
+	var size int
+
+	switch {
+	case req.Calldata != "" && len(req.Topics) == 0 && req.Data == "":
+		calldata, err := hexutil.Decode(req.Calldata)
+		if err != nil || len(calldata) < 4 {
+			return nil, fmt.Errorf("%w: calldata must be hex encoded and at least 4 bytes long", errInvalidDecodeRequest)
+		}
+		hash = hexutil.Encode(calldata[:4])
+		size = len(calldata)
+
+		// revert data looks just like calldata, so try custom errors too
+		decoders[client.SignatureTypeFunction] = func(sig string) ([]*solidity.DecodedValue, error) {
+			return solidity.DecodeCalldata(sig, calldata)
+		}
+		decoders[client.SignatureTypeError] = func(sig string) ([]*solidity.DecodedValue, error) {
+			return solidity.DecodeRevert(sig, calldata)
+		}
+	case req.Calldata == "" && len(req.Topics) > 0:
+		if len(req.Topics) > 4 {
+			return nil, fmt.Errorf("%w: logs have at most 4 topics", errInvalidDecodeRequest)
+		}
+
+		var topics []common.Hash
+		for _, raw := range req.Topics {
+			topic, err := hexutil.Decode(raw)
+			if err != nil || len(topic) != common.HashLength {
+				return nil, fmt.Errorf("%w: topics must be hex encoded 32 byte values", errInvalidDecodeRequest)
+			}
+			topics = append(topics, common.BytesToHash(topic))
+		}
+		hash = topics[0].Hex()
+
+		var data []byte
+		if req.Data != "" {
+			var err error
+			if data, err = hexutil.Decode(req.Data); err != nil {
+				return nil, fmt.Errorf("%w: data must be hex encoded", errInvalidDecodeRequest)
+			}
+		}
+		size = len(data)
+
+		decoders[client.SignatureTypeEvent] = func(sig string) ([]*solidity.DecodedValue, error) {
+			return solidity.DecodeLog(sig, topics, data)
+		}
+	default:
+		return nil, fmt.Errorf("%w: either calldata or topics must be given", errInvalidDecodeRequest)
+	}
+
+	if size > s.config.MaxDecodeDataSize {
+		return nil, fmt.Errorf("%w: at most %d bytes may be decoded at once", errInvalidDecodeRequest, s.config.MaxDecodeDataSize)
+	}
+
+	signatures := client.NewSignatureResponse()
+	for typ := range decoders {
+		var err error
+		if signatures[typ], err = s.db.LoadSignatures(typ, []string{hash}); err != nil {
+			return nil, fmt.Errorf("failed to load signatures: %w", err)
+		}
+	}
+	s.filterResponse(signatures, shouldFilter)
+
+	response := &client.DecodeResponse{
+		Results: []*client.DecodedSignature{},
+	}
+	candidates := make(map[client.SignatureType][]*client.SignatureData)
+	for _, typ := range client.SignatureTypes() {
+		for _, candidate := range signatures[typ][hash] {
+			if response.Candidates < s.config.MaxDecodeCandidates {
+				candidates[typ] = append(candidates[typ], candidate)
+				response.Candidates++
+			} else {
+				response.Skipped++
+			}
+		}
+	}
+
+	if charge != nil {
+		if err := charge(response.Candidates * (1 + size/decodeWorkSize)); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, typ := range client.SignatureTypes() {
+		decode := decoders[typ]
+		for _, candidate := range candidates[typ] {
+			decoded, err := decode(candidate.Name)
+			if err != nil {
+				continue
+			}
+
+			result := &client.DecodedSignature{
+				Type:      typ,
+				Hash:      hash,
+				Name:      candidate.Name,
+				Filtered:  candidate.Filtered,
+				Arguments: make([]*client.DecodedArgument, len(decoded)),
+			}
+			for i, value := range decoded {
+				result.Arguments[i] = &client.DecodedArgument{
+					Type:    value.Type,
+					Value:   value.Value,
+					Indexed: value.Indexed,
+					Hashed:  value.Hashed,
+				}
+			}
+			response.Results = append(response.Results, result)
+		}
+	}
+
+	return response, nil
+}
+
+func (s *Service) serveDecode(w http.ResponseWriter, r *http.Request) {
+	var req client.DecodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		failDecode(w, err)
+		return
+	}
+
+	params := r.URL.Query()
+	shouldFilter := !params.Has("filter") || params.Get("filter") != "false"
+
+	bucket, limit := s.workBucket(r.Context(), core.GetRemoteIP(r))
+	response, err := s.decode(&req, shouldFilter, func(work int) error {
+		return s.chargeWork(r.Context(), bucket, limit, work)
+	})
+	if err != nil {
+		var limited *rateLimitedError
+		if errors.As(err, &limited) {
+			failRateLimited(w, limited.wait)
+			return
+		}
+		if errors.Is(err, errInvalidDecodeRequest) {
+			fail(w, http.StatusBadRequest, err, err.Error())
+			return
+		}
+		fail(w, http.StatusInternalServerError, err, "failed to decode")
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"ip":         core.GetRemoteIP(r),
+		"ua":         core.GetUserAgent(r),
+		"candidates": response.Candidates,
+		"skipped":    response.Skipped,
+		"decoded":    len(response.Results),
+	}).Infof("decoded")
+
+	succeed(w, response)
+}
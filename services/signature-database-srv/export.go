@@ -0,0 +1,475 @@
+package signature_database_srv
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/core"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/notify"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/client"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/database"
+	log "github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// exportSnapshot is a full export, stored as one "id,hash,name" file per type so that any format can be streamed
+// from it without going back to the database
+type exportSnapshot struct {
+	dir     string
+	version int64
+	time    time.Time
+}
+
+func (e *exportSnapshot) path(typ client.SignatureType) string {
+	return path.Join(e.dir, string(typ)+".csv")
+}
+
+// exportVersionInterval is how often the version served by incremental exports is brought up to date, and
+// exportVersionMaxAge how stale it can get before the refresh waits for imports to finish rather than trying again later
+const (
+	exportVersionInterval = 5 * time.Second
+	exportVersionMaxAge   = time.Minute
+)
+
+// runExportVersion keeps the version served by incremental exports up to date. Requests only ever read the last one,
+// so that however many readers there are, they can't hold up imports by queueing on the lock ExportVersion takes
+func (s *Service) runExportVersion() {
+	var refreshed time.Time
+	ticker := time.NewTicker(exportVersionInterval)
+	for ; true; <-ticker.C {
+		version, err := s.db.ExportVersion(time.Since(refreshed) > exportVersionMaxAge)
+		if errors.Is(err, database.ErrImportsInProgress) {
+			continue
+		} else if err != nil {
+			log.WithError(err).Warnf("failed to get export version")
+			continue
+		}
+		refreshed = time.Now()
+
+		s.dataExportLock.Lock()
+		s.exportVersion = version
+		s.dataExportLock.Unlock()
+	}
+}
+
+// currentExportVersion returns the version incremental exports go up to, or -1 if it isn't known yet
+func (s *Service) currentExportVersion() int64 {
+	s.dataExportLock.Lock()
+	defer s.dataExportLock.Unlock()
+	return s.exportVersion
+}
+
+func (s *Service) runExports() {
+	ticker := time.NewTicker(s.config.ExportInterval)
+	for ; true; <-ticker.C {
+		s.refreshExport()
+	}
+}
+
+// refreshExport brings the snapshot up to date, waiting for any refresh which is already underway first
+func (s *Service) refreshExport() {
+	s.exportLock.Lock()
+	defer s.exportLock.Unlock()
+
+	if err := s.exportData(); err != nil {
+		log.WithError(err).Errorf("failed to export data")
+		return
+	}
+
+	s.dataExportLock.Lock()
+	s.lastExport = time.Now()
+	s.dataExportLock.Unlock()
+
+	log.Info("successfully exported data")
+}
+
+func (s *Service) exportData() error {
+	s.dataExportLock.Lock()
+	last := s.dataExport
+	s.dataExportLock.Unlock()
+
+	version, err := s.db.ExportVersion(true)
+	if err != nil {
+		return fmt.Errorf("failed to get export version: %w", err)
+	}
+	if last != nil && last.version == version {
+		return nil
+	}
+
+	started := time.Now()
+	snapshot := &exportSnapshot{
+		dir:     path.Join(s.config.DataDumpDir, uuid.New().String()),
+		version: version,
+		time:    time.Now().UTC().Truncate(time.Second),
+	}
+	if err := os.MkdirAll(snapshot.dir, 0755); err != nil {
+		return err
+	}
+
+	for _, typ := range client.SignatureTypes() {
+		if err := s.exportType(snapshot, typ); err != nil {
+			os.RemoveAll(snapshot.dir)
+			return fmt.Errorf("failed to export %s signatures: %w", typ, err)
+		}
+	}
+
+	s.dataExportLock.Lock()
+	s.dataExport = snapshot
+	s.dataExportLock.Unlock()
+
+	// anyone still downloading the previous snapshot keeps their open file
+	if last != nil {
+		os.RemoveAll(last.dir)
+	}
+
+	_ = s.notifier.Notify(context.Background(), &notify.Message{
+		Event: notify.EventExportCompleted,
+		Title: "Export completed",
+		Text:  fmt.Sprintf("Exported signatures up to version %d in %s", version, time.Since(started).Round(time.Second)),
+		Data: map[string]any{
+			"version": version,
+			"time":    snapshot.time,
+		},
+	})
+
+	return nil
+}
+
+func (s *Service) exportType(snapshot *exportSnapshot, typ client.SignatureType) error {
+	f, err := os.Create(snapshot.path(typ))
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	if err := s.db.ExportSignatures(typ, snapshot.version, func(sig *client.ExportedSignature) error {
+		_, err := fmt.Fprintf(w, "%d,%s,%s\n", sig.ID, sig.Hash, sig.Name)
+		return err
+	}); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// exportWriter writes rows of an export in one of the supported formats. Exports other than txt end with a trailer
+// giving the number of rows, so that readers can tell a complete export from one which was cut short
+type exportWriter interface {
+	Write(sig *client.ExportedSignature) error
+	End(count int64) error
+}
+
+// textExportWriter writes the original "0xhash,name" export, which is kept exactly as it was for the tools which
+// split it into hashes and names, so it has neither types nor a trailer
+type textExportWriter struct {
+	w *bufio.Writer
+}
+
+func (t *textExportWriter) Write(sig *client.ExportedSignature) error {
+	_, err := fmt.Fprintf(t.w, "%s,%s\n", sig.Hash, sig.Name)
+	return err
+}
+
+func (t *textExportWriter) End(count int64) error {
+	return t.w.Flush()
+}
+
+type csvExportWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvExportWriter) Write(sig *client.ExportedSignature) error {
+	return c.w.Write([]string{strconv.FormatInt(sig.ID, 10), string(sig.Type), sig.Hash, sig.Name})
+}
+
+func (c *csvExportWriter) End(count int64) error {
+	if err := c.w.Write([]string{client.ExportTrailerPrefix, strconv.FormatInt(count, 10), "", ""}); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type ndjsonExportWriter struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func (n *ndjsonExportWriter) Write(sig *client.ExportedSignature) error {
+	return n.enc.Encode(sig)
+}
+
+func (n *ndjsonExportWriter) End(count int64) error {
+	if err := n.enc.Encode(&client.ExportTrailer{End: true, Count: count}); err != nil {
+		return err
+	}
+	return n.w.Flush()
+}
+
+func newExportWriter(w io.Writer, format client.ExportFormat) (exportWriter, error) {
+	switch format {
+	case client.ExportFormatCSV:
+		c := csv.NewWriter(w)
+		if err := c.Write([]string{"id", "type", "hash", "name"}); err != nil {
+			return nil, err
+		}
+		return &csvExportWriter{w: c}, nil
+	case client.ExportFormatNDJSON:
+		b := bufio.NewWriter(w)
+		return &ndjsonExportWriter{w: b, enc: json.NewEncoder(b)}, nil
+	default:
+		return &textExportWriter{w: bufio.NewWriter(w)}, nil
+	}
+}
+
+var exportContentTypes = map[client.ExportFormat]string{
+	client.ExportFormatText:   "text/plain",
+	client.ExportFormatCSV:    "text/csv",
+	client.ExportFormatNDJSON: "application/x-ndjson",
+}
+
+// notModified handles conditional requests, preferring the etag as http.ServeContent does
+func notModified(r *http.Request, etag string, modified time.Time) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == etag || candidate == "*" {
+				return true
+			}
+		}
+		return false
+	}
+
+	if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !modified.IsZero() {
+		return !modified.After(since)
+	}
+	return false
+}
+
+func (s *Service) serveExport(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	format := client.ExportFormatText
+	if params.Has("format") {
+		format = client.ExportFormat(params.Get("format"))
+		if !format.Valid() {
+			fail(w, http.StatusBadRequest, nil, "format must be one of txt, csv or ndjson")
+			return
+		}
+	}
+
+	// the original txt export only had functions and events, and errors can't be told apart from functions without a
+	// type column, so they're only in txt exports which ask for them
+	types := client.SignatureTypes()
+	if format == client.ExportFormatText {
+		types = []client.SignatureType{client.SignatureTypeFunction, client.SignatureTypeEvent}
+	}
+	if params.Has("type") {
+		typ := client.SignatureType(params.Get("type"))
+		if !typ.Valid() {
+			fail(w, http.StatusBadRequest, nil, "invalid signature type")
+			return
+		}
+		types = []client.SignatureType{typ}
+	}
+
+	compress := params.Get("compress") == "gzip"
+
+	// since_id=0 is how mirrors start out, and needs to be ordered by id like any other incremental export
+	incremental := params.Has("since_id") || params.Has("since")
+	since, err := parseExportSince(params.Get("since_id"), params.Get("since"))
+	if err != nil {
+		fail(w, http.StatusBadRequest, err, err.Error())
+		return
+	}
+
+	var (
+		version  int64
+		modified time.Time
+		snapshot *exportSnapshot
+	)
+	if !incremental {
+		s.dataExportLock.Lock()
+		snapshot = s.dataExport
+		s.dataExportLock.Unlock()
+
+		if snapshot == nil {
+			fail(w, http.StatusServiceUnavailable, nil, "export is not ready yet")
+			return
+		}
+		version, modified = snapshot.version, snapshot.time
+	} else if version = s.currentExportVersion(); version < 0 {
+		fail(w, http.StatusServiceUnavailable, nil, "export is not ready yet")
+		return
+	}
+
+	filename := "export"
+	if len(types) == 1 {
+		filename += "-" + string(types[0])
+	}
+
+	// every variant of the same version is a different representation, so needs its own etag
+	etag := []string{strconv.FormatInt(version, 10), filename, string(format)}
+	if incremental {
+		etag = append(etag, "since", strconv.FormatInt(since.ID, 10), strconv.FormatInt(since.Time.Unix(), 10))
+	}
+	if compress {
+		etag = append(etag, "gzip")
+	}
+
+	filename += "." + string(format)
+
+	w.Header().Set("ETag", `"`+strings.Join(etag, "-")+`"`)
+	w.Header().Set("X-Export-Version", strconv.FormatInt(version, 10))
+	if !modified.IsZero() {
+		w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+	}
+	if notModified(r, w.Header().Get("ETag"), modified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"ip":       core.GetRemoteIP(r),
+		"ua":       core.GetUserAgent(r),
+		"format":   format,
+		"types":    types,
+		"since_id": since.ID,
+		"since":    since.Time,
+		"version":  version,
+		"gzip":     compress,
+	}).Infof("serving export")
+
+	var (
+		ew    exportWriter
+		gz    *gzip.Writer
+		count int64
+	)
+	// the response is only started once the first row has been read, so that failing to read anything can still be
+	// reported with an error status. after that it's too late, so the response is cut short without its trailer
+	start := func() error {
+		var out io.Writer = w
+		if compress {
+			filename += ".gz"
+			w.Header().Set("Content-Type", "application/gzip")
+
+			gz, _ = gzip.NewWriterLevel(w, gzip.BestSpeed)
+			out = gz
+		} else {
+			w.Header().Set("Content-Type", exportContentTypes[format])
+		}
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+		ew, err = newExportWriter(out, format)
+		return err
+	}
+	write := func(sig *client.ExportedSignature) error {
+		// only ndjson has a way to mark signatures as removed
+		if sig.Removed && format != client.ExportFormatNDJSON {
+			return nil
+		}
+		if ew == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		count++
+		return ew.Write(sig)
+	}
+
+	for _, typ := range types {
+		if snapshot != nil {
+			err = streamSnapshot(snapshot, typ, write)
+		} else {
+			err = s.db.ExportChanges(typ, version, since, write)
+		}
+		if err != nil && ew == nil {
+			fail(w, http.StatusInternalServerError, err, "failed to export signatures")
+			return
+		} else if err != nil {
+			log.WithError(err).Errorf("failed to stream export")
+			return
+		}
+	}
+
+	if ew == nil {
+		if err := start(); err != nil {
+			log.WithError(err).Errorf("failed to start export")
+			return
+		}
+	}
+	if err := ew.End(count); err != nil {
+		log.WithError(err).Errorf("failed to finish export")
+		return
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			log.WithError(err).Errorf("failed to finish compressed export")
+		}
+	}
+}
+
+func parseExportSince(sinceID string, since string) (database.ExportSince, error) {
+	var params database.ExportSince
+	if sinceID != "" {
+		id, err := strconv.ParseInt(sinceID, 10, 64)
+		if err != nil || id < 0 {
+			return params, fmt.Errorf("since_id must be a non-negative integer")
+		}
+		params.ID = id
+	}
+	if since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			return params, fmt.Errorf("since must be an RFC 3339 timestamp")
+		}
+		params.Time = t
+	}
+	return params, nil
+}
+
+func streamSnapshot(snapshot *exportSnapshot, typ client.SignatureType, apply func(*client.ExportedSignature) error) error {
+	f, err := os.Open(snapshot.path(typ))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ",", 3)
+		if len(parts) != 3 {
+			return fmt.Errorf("malformed export line: %s", scanner.Text())
+		}
+		id, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("malformed export line: %s", scanner.Text())
+		}
+		if err := apply(&client.ExportedSignature{
+			ID:   id,
+			Type: typ,
+			Hash: parts[1],
+			Name: parts[2],
+		}); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
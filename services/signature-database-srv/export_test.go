@@ -0,0 +1,72 @@
+package signature_database_srv
+
+import (
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/bulk"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/client"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func Test_ServeExport(t *testing.T) {
+	snapshot := &exportSnapshot{dir: t.TempDir(), version: 3, time: time.Now()}
+	assert.NoError(t, os.WriteFile(snapshot.path(client.SignatureTypeFunction), []byte("1,0xa9059cbb,transfer(address,uint256)\n3,0x095ea7b3,approve(address,uint256)\n"), 0644))
+	assert.NoError(t, os.WriteFile(snapshot.path(client.SignatureTypeEvent), []byte("2,0xddf252ad,Transfer(address,address,uint256)\n"), 0644))
+
+	s := &Service{config: &Config{}, dataExport: snapshot, exportVersion: -1}
+
+	export := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		s.serveExport(rec, httptest.NewRequest("GET", "/v1/export?"+query, nil))
+		return rec
+	}
+
+	// txt is the original export, with only functions and events and no trailer, so the missing errors don't matter
+	rec := export("")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "0xa9059cbb,transfer(address,uint256)\n0x095ea7b3,approve(address,uint256)\n0xddf252ad,Transfer(address,address,uint256)\n", rec.Body.String())
+
+	rec = export("type=function")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "0xa9059cbb,transfer(address,uint256)\n0x095ea7b3,approve(address,uint256)\n", rec.Body.String())
+
+	rec = export("type=event&format=csv")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "id,type,hash,name\n2,event,0xddf252ad,\"Transfer(address,address,uint256)\"\n#end,1,,\n", rec.Body.String())
+
+	rec = export("type=event&format=ndjson")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"id":2,"type":"event","hash":"0xddf252ad","name":"Transfer(address,address,uint256)"}`+"\n"+`{"end":true,"count":1}`+"\n", rec.Body.String())
+
+	// failing before anything has been sent is still reported as a failure, rather than an empty export
+	rec = export("type=error")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.NotContains(t, rec.Body.String(), "#end")
+
+	// the bulk importer reads exports back, checking them against their trailer
+	assert.NoError(t, os.WriteFile(snapshot.path(client.SignatureTypeError), nil, 0644))
+	for _, format := range []bulk.Format{bulk.FormatText, bulk.FormatCSV, bulk.FormatNDJSON} {
+		r, err := bulk.NewReader(export("format="+string(format)).Body, format, client.SignatureTypeFunction)
+		assert.NoError(t, err)
+
+		var names []string
+		for {
+			entry, err := r.Next()
+			if err != nil {
+				assert.ErrorIs(t, err, io.EOF, format)
+				break
+			}
+			names = append(names, entry.Name)
+		}
+		assert.Equal(t, []string{"transfer(address,uint256)", "approve(address,uint256)", "Transfer(address,address,uint256)"}, names, format)
+	}
+
+	// incremental exports wait until the version they go up to is known
+	rec = export("since_id=0&format=csv")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
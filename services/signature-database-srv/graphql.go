@@ -0,0 +1,658 @@
+package signature_database_srv
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/client"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/database"
+	log "github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const graphqlSchema = `
+schema {
+  query: Query
+}
+
+enum SignatureType {
+  FUNCTION
+  EVENT
+  ERROR
+}
+
+enum CollisionClassification {
+  BENIGN
+  SUSPICIOUS
+}
+
+type Query {
+  # signatures of each function selector, in the order given
+  functions(hashes: [String!]!): [Hash!]!
+  # signatures of each event topic, in the order given
+  events(hashes: [String!]!): [Hash!]!
+  # signatures of each error selector, in the order given
+  errors(hashes: [String!]!): [Hash!]!
+  # search matches query against the full signature with '*' and '?' as wildcards, name against a substring of the
+  # name, args against the exact argument list and param against the type of any argument
+  search(query: String, name: String, args: String, param: String, type: SignatureType, cursor: String, limit: Int = 100, filter: Boolean = true): SearchResults!
+  stats: Stats!
+  # collisions, most recently updated first. since is an RFC 3339 timestamp
+  collisions(type: SignatureType, classification: CollisionClassification, since: String, limit: Int = 100, offset: Int = 0): [Collision!]!
+}
+
+type Hash {
+  type: SignatureType!
+  hash: String!
+  # signatures, without the function signatures which aren't the canonical one for the hash unless filter is false
+  signatures(filter: Boolean = true): [Signature!]!
+  # the canonical function signature for the hash, if one is known
+  canonical: String
+  # the collision between the signatures of the hash, if they collide
+  collision: Collision
+}
+
+type Signature {
+  name: String!
+  filtered: Boolean!
+  score: Int!
+  createdAt: String
+  source: String
+  submitter: String
+}
+
+type SearchResults {
+  results: [SearchResult!]!
+  nextCursor: String
+}
+
+type SearchResult {
+  type: SignatureType!
+  hash: String!
+  signature: Signature!
+  canonical: String
+  collision: Collision
+}
+
+type Stats {
+  functions: Int!
+  events: Int!
+  errors: Int!
+}
+
+type Collision {
+  type: SignatureType!
+  hash: String!
+  classification: CollisionClassification!
+  reason: String
+  signatures: [Signature!]!
+  detectedAt: String!
+  updatedAt: String!
+}
+`
+
+// maxGraphQLBatch is the most queries which may be sent in one request as a json array
+const maxGraphQLBatch = 20
+
+// graphqlStatsCost is what stats is charged against a request's budget, since it counts every signature. Other root
+// fields are charged a unit for each hash they look up or result they may return
+const graphqlStatsCost = 100
+
+func newGraphQLSchema(s *Service) (*graphql.Schema, error) {
+	return graphql.ParseSchema(graphqlSchema, &graphqlResolver{s: s}, graphql.MaxDepth(8))
+}
+
+type graphqlRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// serveGraphQL executes a query, or a json array of them, sharing one loader between everything in the request so
+// that the hashes they ask about are loaded together. Responses follow the graphql spec rather than our own envelope
+func (s *Service) serveGraphQL(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		graphqlFail(w, http.StatusRequestEntityTooLarge, err, fmt.Sprintf("body too large: at most %d bytes may be sent", maxBytesErr.Limit))
+		return
+	} else if err != nil {
+		graphqlFail(w, http.StatusBadRequest, err, "failed to read body")
+		return
+	}
+
+	batched := bytes.HasPrefix(bytes.TrimSpace(body), []byte("["))
+	var reqs []*graphqlRequest
+	if batched {
+		err = json.Unmarshal(body, &reqs)
+	} else {
+		reqs = []*graphqlRequest{{}}
+		err = json.Unmarshal(body, reqs[0])
+	}
+	if err != nil {
+		graphqlFail(w, http.StatusBadRequest, err, "failed to decode body")
+		return
+	}
+	if len(reqs) == 0 || len(reqs) > maxGraphQLBatch {
+		graphqlFail(w, http.StatusBadRequest, nil, fmt.Sprintf("between 1 and %d queries may be sent at once", maxGraphQLBatch))
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), graphqlLoaderKey{}, newGraphQLLoader(s))
+
+	responses := make([]*graphql.Response, len(reqs))
+	for i, req := range reqs {
+		responses[i] = s.graphql.Exec(ctx, req.Query, req.OperationName, req.Variables)
+	}
+
+	fields := requestFields(r)
+	fields["queries"] = len(reqs)
+	log.WithFields(fields).Infof("served graphql")
+
+	w.Header().Set("Content-Type", "application/json")
+	if batched {
+		json.NewEncoder(w).Encode(responses)
+	} else {
+		json.NewEncoder(w).Encode(responses[0])
+	}
+}
+
+func graphqlFail(w http.ResponseWriter, status int, err error, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	log.WithError(err).Errorf(msg)
+	json.NewEncoder(w).Encode(map[string]any{
+		"errors": []map[string]any{{"message": msg}},
+	})
+}
+
+type graphqlLoaderKey struct{}
+
+// graphqlBatch loads values for hashes of each type together. Hashes are registered as the resolvers which may need
+// them are created, and the first time any of them is needed everything registered so far is loaded in one query
+type graphqlBatch[T any] struct {
+	load func(typ client.SignatureType, hashes []string) (map[string]T, error)
+
+	lock    sync.Mutex
+	pending map[client.SignatureType]map[string]struct{}
+	loaded  map[client.SignatureType]map[string]T
+}
+
+func newGraphQLBatch[T any](load func(typ client.SignatureType, hashes []string) (map[string]T, error)) *graphqlBatch[T] {
+	return &graphqlBatch[T]{
+		load:    load,
+		pending: make(map[client.SignatureType]map[string]struct{}),
+		loaded:  make(map[client.SignatureType]map[string]T),
+	}
+}
+
+func (b *graphqlBatch[T]) want(typ client.SignatureType, hash string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if _, ok := b.loaded[typ][hash]; ok {
+		return
+	}
+	if b.pending[typ] == nil {
+		b.pending[typ] = make(map[string]struct{})
+	}
+	b.pending[typ][hash] = struct{}{}
+}
+
+func (b *graphqlBatch[T]) get(typ client.SignatureType, hash string) (T, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if value, ok := b.loaded[typ][hash]; ok {
+		return value, nil
+	}
+
+	hashes := []string{hash}
+	for pending := range b.pending[typ] {
+		if pending != hash {
+			hashes = append(hashes, pending)
+		}
+	}
+	delete(b.pending, typ)
+
+	values, err := b.load(typ, hashes)
+	if err != nil {
+		var empty T
+		return empty, err
+	}
+
+	if b.loaded[typ] == nil {
+		b.loaded[typ] = make(map[string]T)
+	}
+	for _, h := range hashes {
+		b.loaded[typ][h] = values[h]
+	}
+	return values[hash], nil
+}
+
+// graphqlLoader holds everything loaded while serving one request
+type graphqlLoader struct {
+	s *Service
+
+	lock   sync.Mutex
+	hashes int
+	cost   int
+
+	signatures *graphqlBatch[[]*client.SignatureData]
+	collisions *graphqlBatch[*client.Collision]
+}
+
+func newGraphQLLoader(s *Service) *graphqlLoader {
+	return &graphqlLoader{
+		s:          s,
+		signatures: newGraphQLBatch(s.lookupSignatures),
+		collisions: newGraphQLBatch(s.db.LoadCollisions),
+	}
+}
+
+func graphqlLoaderFromContext(ctx context.Context) *graphqlLoader {
+	return ctx.Value(graphqlLoaderKey{}).(*graphqlLoader)
+}
+
+// charge spends from the request's budget, which is shared by every root field in every query of the request so that
+// aliases and batching can't multiply how much work one request does
+func (l *graphqlLoader) charge(cost int) error {
+	l.lock.Lock()
+	l.cost += cost
+	total := l.cost
+	l.lock.Unlock()
+
+	if total > l.s.config.MaxGraphQLCost {
+		return fmt.Errorf("query too expensive: at most %d hashes, search results or collisions may be asked for at once, where stats counts as %d", l.s.config.MaxGraphQLCost, graphqlStatsCost)
+	}
+	return nil
+}
+
+// want registers a hash with every batch. Nothing is loaded until a resolver asks for it, so this is free
+func (l *graphqlLoader) want(typ client.SignatureType, hash string) {
+	l.signatures.want(typ, hash)
+	l.collisions.want(typ, hash)
+}
+
+func (l *graphqlLoader) loadSignatures(typ client.SignatureType, hash string) ([]*client.SignatureData, error) {
+	sigs, err := l.signatures.get(typ, hash)
+	if err != nil {
+		log.WithError(err).Errorf("failed to load signatures")
+		return nil, errors.New("failed to load signatures")
+	}
+	return sigs, nil
+}
+
+// filteredSignatures returns the signatures of a hash marked, and filtered if asked, by filterResponse like the other
+// apis. The loaded signatures are shared by every field asking about the hash, so they're copied rather than marked
+func (l *graphqlLoader) filteredSignatures(typ client.SignatureType, hash string, shouldFilter bool) ([]*graphqlSignatureResolver, error) {
+	sigs, err := l.loadSignatures(typ, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	response := client.NewSignatureResponse()
+	response[typ][hash] = []*client.SignatureData{}
+	for _, sig := range sigs {
+		copied := *sig
+		response[typ][hash] = append(response[typ][hash], &copied)
+	}
+	l.s.filterResponse(response, shouldFilter)
+
+	results := []*graphqlSignatureResolver{}
+	for _, sig := range response[typ][hash] {
+		results = append(results, &graphqlSignatureResolver{sig: sig})
+	}
+	return results, nil
+}
+
+func (l *graphqlLoader) loadCollision(typ client.SignatureType, hash string) (*client.Collision, error) {
+	collision, err := l.collisions.get(typ, hash)
+	if err != nil {
+		log.WithError(err).Errorf("failed to load collisions")
+		return nil, errors.New("failed to load collisions")
+	}
+	return collision, nil
+}
+
+// canonical returns the canonical signature for a function selector, or nil if there isn't one
+func (l *graphqlLoader) canonical(typ client.SignatureType, hash string) *string {
+	if typ != client.SignatureTypeFunction {
+		return nil
+	}
+
+	l.s.canonicalSignaturesLock.RLock()
+	defer l.s.canonicalSignaturesLock.RUnlock()
+
+	if expected, ok := l.s.canonicalSignatures[hash]; ok {
+		return &expected
+	}
+	return nil
+}
+
+// graphqlString returns the value of an optional argument, or "" if it wasn't given
+func graphqlString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func graphqlEnum[T ~string](value T) string {
+	return strings.ToUpper(string(value))
+}
+
+type graphqlResolver struct {
+	s *Service
+}
+
+type hashesArgs struct {
+	Hashes []string
+}
+
+func (g *graphqlResolver) Functions(ctx context.Context, args hashesArgs) ([]*graphqlHashResolver, error) {
+	return g.lookup(ctx, client.SignatureTypeFunction, args.Hashes)
+}
+
+func (g *graphqlResolver) Events(ctx context.Context, args hashesArgs) ([]*graphqlHashResolver, error) {
+	return g.lookup(ctx, client.SignatureTypeEvent, args.Hashes)
+}
+
+func (g *graphqlResolver) Errors(ctx context.Context, args hashesArgs) ([]*graphqlHashResolver, error) {
+	return g.lookup(ctx, client.SignatureTypeError, args.Hashes)
+}
+
+func (g *graphqlResolver) lookup(ctx context.Context, typ client.SignatureType, hashes []string) ([]*graphqlHashResolver, error) {
+	loader := graphqlLoaderFromContext(ctx)
+
+	// the limit applies to the whole request, so that it can't be sidestepped with aliases or batching
+	loader.lock.Lock()
+	loader.hashes += len(hashes)
+	count := loader.hashes
+	loader.lock.Unlock()
+	if err := g.s.checkLookupSize(count); err != nil {
+		return nil, err
+	}
+	if err := loader.charge(len(hashes)); err != nil {
+		return nil, err
+	}
+
+	var results []*graphqlHashResolver
+	for _, hash := range hashes {
+		if _, err := hexutil.Decode(hash); err != nil {
+			return nil, fmt.Errorf("invalid hash: %s", hash)
+		}
+		hash = strings.ToLower(hash)
+
+		loader.want(typ, hash)
+		results = append(results, &graphqlHashResolver{loader: loader, typ: typ, hash: hash})
+	}
+	return results, nil
+}
+
+func (g *graphqlResolver) Search(ctx context.Context, args struct {
+	Query  *string
+	Name   *string
+	Args   *string
+	Param  *string
+	Type   *string
+	Cursor *string
+	Limit  int32
+	Filter bool
+}) (*graphqlSearchResolver, error) {
+	loader := graphqlLoaderFromContext(ctx)
+
+	if args.Limit <= 0 || args.Limit > maxSearchLimit {
+		return nil, fmt.Errorf("limit must be between 1 and %d", maxSearchLimit)
+	}
+	if err := loader.charge(int(args.Limit)); err != nil {
+		return nil, err
+	}
+
+	response, err := g.s.db.QuerySignatures(&database.SearchFilter{
+		Query: graphqlString(args.Query),
+		Name:  graphqlString(args.Name),
+		Args:  graphqlString(args.Args),
+		Param: graphqlString(args.Param),
+		Type:  client.SignatureType(strings.ToLower(graphqlString(args.Type))),
+	}, graphqlString(args.Cursor), int(args.Limit))
+	if err != nil {
+		if errors.Is(err, database.ErrInvalidCursor) || errors.Is(err, database.ErrInvalidSearch) {
+			return nil, err
+		}
+		log.WithError(err).Errorf("failed to query signatures")
+		return nil, errors.New("failed to query signatures")
+	}
+
+	g.s.filterSearchResponse(response, args.Filter)
+
+	result := &graphqlSearchResolver{}
+	if response.NextCursor != "" {
+		result.nextCursor = &response.NextCursor
+	}
+	for _, typ := range client.SignatureTypes() {
+		for _, v := range response.Results[typ] {
+			loader.want(typ, v.Hash)
+			result.results = append(result.results, &graphqlSearchResultResolver{
+				graphqlHashResolver: &graphqlHashResolver{loader: loader, typ: typ, hash: v.Hash},
+				signature:           v.SignatureData,
+			})
+		}
+	}
+	return result, nil
+}
+
+func (g *graphqlResolver) Stats(ctx context.Context) (*graphqlStatsResolver, error) {
+	if err := graphqlLoaderFromContext(ctx).charge(graphqlStatsCost); err != nil {
+		return nil, err
+	}
+
+	stats := &graphqlStatsResolver{counts: make(map[client.SignatureType]int32)}
+	for _, typ := range client.SignatureTypes() {
+		count, err := g.s.db.CountSignatures(typ)
+		if err != nil {
+			log.WithError(err).Errorf("failed to count signatures")
+			return nil, errors.New("failed to count signatures")
+		}
+		stats.counts[typ] = int32(count)
+	}
+	return stats, nil
+}
+
+func (g *graphqlResolver) Collisions(ctx context.Context, args struct {
+	Type           *string
+	Classification *string
+	Since          *string
+	Limit          int32
+	Offset         int32
+}) ([]*graphqlCollisionResolver, error) {
+	loader := graphqlLoaderFromContext(ctx)
+
+	if args.Limit <= 0 || args.Limit > maxCollisionsLimit {
+		return nil, fmt.Errorf("limit must be between 1 and %d", maxCollisionsLimit)
+	}
+	if args.Offset < 0 {
+		return nil, errors.New("offset must be a non-negative integer")
+	}
+	if err := loader.charge(int(args.Limit)); err != nil {
+		return nil, err
+	}
+
+	filter := &database.CollisionFilter{
+		Type:           client.SignatureType(strings.ToLower(graphqlString(args.Type))),
+		Classification: client.CollisionClassification(strings.ToLower(graphqlString(args.Classification))),
+	}
+	if args.Since != nil {
+		since, err := time.Parse(time.RFC3339, *args.Since)
+		if err != nil {
+			return nil, errors.New("since must be an RFC 3339 timestamp")
+		}
+		filter.Since = since
+	}
+
+	collisions, err := g.s.db.QueryCollisions(filter, int(args.Limit), int(args.Offset))
+	if err != nil {
+		log.WithError(err).Errorf("failed to query collisions")
+		return nil, errors.New("failed to query collisions")
+	}
+
+	var results []*graphqlCollisionResolver
+	for _, collision := range collisions {
+		loader.want(collision.Type, collision.Hash)
+		results = append(results, &graphqlCollisionResolver{loader: loader, collision: collision})
+	}
+	return results, nil
+}
+
+type graphqlHashResolver struct {
+	loader *graphqlLoader
+	typ    client.SignatureType
+	hash   string
+}
+
+func (h *graphqlHashResolver) Type() string {
+	return graphqlEnum(h.typ)
+}
+
+func (h *graphqlHashResolver) Hash() string {
+	return h.hash
+}
+
+func (h *graphqlHashResolver) Signatures(args struct{ Filter bool }) ([]*graphqlSignatureResolver, error) {
+	return h.loader.filteredSignatures(h.typ, h.hash, args.Filter)
+}
+
+func (h *graphqlHashResolver) Canonical() *string {
+	return h.loader.canonical(h.typ, h.hash)
+}
+
+func (h *graphqlHashResolver) Collision() (*graphqlCollisionResolver, error) {
+	collision, err := h.loader.loadCollision(h.typ, h.hash)
+	if err != nil || collision == nil {
+		return nil, err
+	}
+	return &graphqlCollisionResolver{loader: h.loader, collision: collision}, nil
+}
+
+type graphqlSignatureResolver struct {
+	sig *client.SignatureData
+}
+
+func (s *graphqlSignatureResolver) Name() string {
+	return s.sig.Name
+}
+
+func (s *graphqlSignatureResolver) Filtered() bool {
+	return s.sig.Filtered
+}
+
+func (s *graphqlSignatureResolver) Score() int32 {
+	return int32(s.sig.Score)
+}
+
+func (s *graphqlSignatureResolver) CreatedAt() *string {
+	if s.sig.CreatedAt == nil {
+		return nil
+	}
+	createdAt := s.sig.CreatedAt.Format(time.RFC3339)
+	return &createdAt
+}
+
+func (s *graphqlSignatureResolver) Source() *string {
+	if s.sig.Source == "" {
+		return nil
+	}
+	source := string(s.sig.Source)
+	return &source
+}
+
+func (s *graphqlSignatureResolver) Submitter() *string {
+	if s.sig.Submitter == "" {
+		return nil
+	}
+	return &s.sig.Submitter
+}
+
+type graphqlSearchResolver struct {
+	results    []*graphqlSearchResultResolver
+	nextCursor *string
+}
+
+func (s *graphqlSearchResolver) Results() []*graphqlSearchResultResolver {
+	return s.results
+}
+
+func (s *graphqlSearchResolver) NextCursor() *string {
+	return s.nextCursor
+}
+
+type graphqlSearchResultResolver struct {
+	*graphqlHashResolver
+	signature *client.SignatureData
+}
+
+func (s *graphqlSearchResultResolver) Signature() *graphqlSignatureResolver {
+	return &graphqlSignatureResolver{sig: s.signature}
+}
+
+type graphqlStatsResolver struct {
+	counts map[client.SignatureType]int32
+}
+
+func (s *graphqlStatsResolver) Functions() int32 {
+	return s.counts[client.SignatureTypeFunction]
+}
+
+func (s *graphqlStatsResolver) Events() int32 {
+	return s.counts[client.SignatureTypeEvent]
+}
+
+func (s *graphqlStatsResolver) Errors() int32 {
+	return s.counts[client.SignatureTypeError]
+}
+
+type graphqlCollisionResolver struct {
+	loader    *graphqlLoader
+	collision *client.Collision
+}
+
+func (c *graphqlCollisionResolver) Type() string {
+	return graphqlEnum(c.collision.Type)
+}
+
+func (c *graphqlCollisionResolver) Hash() string {
+	return c.collision.Hash
+}
+
+func (c *graphqlCollisionResolver) Classification() string {
+	return graphqlEnum(c.collision.Classification)
+}
+
+func (c *graphqlCollisionResolver) Reason() *string {
+	if c.collision.Reason == "" {
+		return nil
+	}
+	return &c.collision.Reason
+}
+
+// Signatures are every signature in the collision, marked but never filtered since the collision is between all of them
+func (c *graphqlCollisionResolver) Signatures() ([]*graphqlSignatureResolver, error) {
+	return c.loader.filteredSignatures(c.collision.Type, c.collision.Hash, false)
+}
+
+func (c *graphqlCollisionResolver) DetectedAt() string {
+	return c.collision.DetectedAt.Format(time.RFC3339)
+}
+
+func (c *graphqlCollisionResolver) UpdatedAt() string {
+	return c.collision.UpdatedAt.Format(time.RFC3339)
+}
@@ -0,0 +1,148 @@
+package signature_database_srv
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/client"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func Test_GraphQLBatch(t *testing.T) {
+	var (
+		lock  sync.Mutex
+		loads [][]string
+	)
+	batch := newGraphQLBatch(func(typ client.SignatureType, hashes []string) (map[string]string, error) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		loads = append(loads, hashes)
+		result := make(map[string]string)
+		for _, hash := range hashes {
+			result[hash] = string(typ) + ":" + hash
+		}
+		return result, nil
+	})
+
+	hashes := []string{"0x01", "0x02", "0x03"}
+	for _, hash := range hashes {
+		batch.want(client.SignatureTypeFunction, hash)
+	}
+
+	var wg sync.WaitGroup
+	for _, hash := range hashes {
+		wg.Add(1)
+		go func(hash string) {
+			defer wg.Done()
+			value, err := batch.get(client.SignatureTypeFunction, hash)
+			assert.NoError(t, err)
+			assert.Equal(t, "function:"+hash, value)
+		}(hash)
+	}
+	wg.Wait()
+
+	assert.Len(t, loads, 1)
+	sort.Strings(loads[0])
+	assert.Equal(t, hashes, loads[0])
+
+	// hashes which were never registered are still loaded, just on their own
+	value, err := batch.get(client.SignatureTypeEvent, "0x04")
+	assert.NoError(t, err)
+	assert.Equal(t, "event:0x04", value)
+	assert.Len(t, loads, 2)
+}
+
+func Test_ServeGraphQL(t *testing.T) {
+	s := &Service{
+		config:              &Config{MaxLookupHashes: 3, MaxGraphQLCost: 100},
+		canonicalSignatures: map[string]string{"0xa9059cbb": "transfer(address,uint256)"},
+	}
+	var err error
+	s.graphql, err = newGraphQLSchema(s)
+	assert.NoError(t, err)
+
+	serve := func(body string) (int, []byte) {
+		w := httptest.NewRecorder()
+		s.serveGraphQL(w, httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewBufferString(body)))
+		return w.Code, w.Body.Bytes()
+	}
+
+	status, body := serve(`{"query": "{ functions(hashes: [\"0xA9059CBB\", \"0x095ea7b3\"]) { type hash canonical } }"}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"data": {"functions": [
+		{"type": "FUNCTION", "hash": "0xa9059cbb", "canonical": "transfer(address,uint256)"},
+		{"type": "FUNCTION", "hash": "0x095ea7b3", "canonical": null}
+	]}}`, string(body))
+
+	// the lookup limit applies across every query in a batch
+	status, body = serve(`[
+		{"query": "query($h: [String!]!) { functions(hashes: $h) { hash } }", "variables": {"h": ["0x01", "0x02"]}},
+		{"query": "{ events(hashes: [\"0x03\", \"0x04\"]) { hash } }"}
+	]`)
+	assert.Equal(t, http.StatusOK, status)
+	var responses []struct {
+		Data   map[string]any `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	assert.NoError(t, json.Unmarshal(body, &responses))
+	assert.Len(t, responses, 2)
+	assert.Empty(t, responses[0].Errors)
+	assert.Equal(t, "too many hashes: at most 3 may be looked up at once", responses[1].Errors[0].Message)
+
+	status, body = serve(`{ functions(hashes: ["0x01"]) { hash } }`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.JSONEq(t, `{"errors": [{"message": "failed to decode body"}]}`, string(body))
+
+	// every root field is charged to the request's budget, so aliases can't repeat an expensive one for free
+	s.config.MaxLookupHashes, s.config.MaxGraphQLCost = 250, 3
+	for _, query := range []string{
+		`{ stats { functions } }`,
+		`{ search(query: \"transfer*\", limit: 5) { nextCursor } }`,
+		`{ collisions(limit: 5) { hash } }`,
+		`{ a: functions(hashes: [\"0x01\", \"0x02\"]) { hash } b: events(hashes: [\"0x03\", \"0x04\"]) { hash } }`,
+	} {
+		status, body = serve(`{"query": "` + query + `"}`)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Contains(t, string(body), "query too expensive", query)
+	}
+}
+
+func Test_GraphQLFilteredSignatures(t *testing.T) {
+	s := &Service{canonicalSignatures: map[string]string{"0xa9059cbb": "transfer(address,uint256)"}}
+	loader := &graphqlLoader{
+		s: s,
+		signatures: newGraphQLBatch(func(typ client.SignatureType, hashes []string) (map[string][]*client.SignatureData, error) {
+			return map[string][]*client.SignatureData{
+				"0xa9059cbb": {{Name: "transfer(address,uint256)"}, {Name: "many_msg_babbage(bytes1)"}},
+			}, nil
+		}),
+	}
+
+	names := func(sigs []*graphqlSignatureResolver) map[string]bool {
+		result := make(map[string]bool)
+		for _, sig := range sigs {
+			result[sig.Name()] = sig.Filtered()
+		}
+		return result
+	}
+
+	sigs, err := loader.filteredSignatures(client.SignatureTypeFunction, "0xa9059cbb", true)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"transfer(address,uint256)": false}, names(sigs))
+
+	sigs, err = loader.filteredSignatures(client.SignatureTypeFunction, "0xa9059cbb", false)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"transfer(address,uint256)": false, "many_msg_babbage(bytes1)": true}, names(sigs))
+
+	// the loaded signatures are shared with other fields, so they're left as they were
+	loaded, err := loader.loadSignatures(client.SignatureTypeFunction, "0xa9059cbb")
+	assert.NoError(t, err)
+	assert.False(t, loaded[1].Filtered)
+}
@@ -0,0 +1,456 @@
+package signature_database_srv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/auth"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/core"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/ratelimit"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/client"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/database"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/pb"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var grpcRoles = map[string]auth.Role{
+	"/signaturedatabase.v1.SignatureDatabase/Lookup": auth.RoleReadOnly,
+	"/signaturedatabase.v1.SignatureDatabase/Search": auth.RoleReadOnly,
+	"/signaturedatabase.v1.SignatureDatabase/Import": auth.RoleImporter,
+	"/signaturedatabase.v1.SignatureDatabase/Stats":  auth.RoleReadOnly,
+	"/signaturedatabase.v1.SignatureDatabase/Export": auth.RoleReadOnly,
+}
+
+var (
+	pbSignatureTypes = map[client.SignatureType]pb.SignatureType{
+		client.SignatureTypeFunction: pb.SignatureType_SIGNATURE_TYPE_FUNCTION,
+		client.SignatureTypeEvent:    pb.SignatureType_SIGNATURE_TYPE_EVENT,
+		client.SignatureTypeError:    pb.SignatureType_SIGNATURE_TYPE_ERROR,
+	}
+	pbSignatureSources = map[client.SignatureSource]pb.SignatureSource{
+		client.SignatureSourceImport:    pb.SignatureSource_SIGNATURE_SOURCE_IMPORT,
+		client.SignatureSourceABI:       pb.SignatureSource_SIGNATURE_SOURCE_ABI,
+		client.SignatureSourceCompiled:  pb.SignatureSource_SIGNATURE_SOURCE_COMPILED,
+		client.SignatureSourceCanonical: pb.SignatureSource_SIGNATURE_SOURCE_CANONICAL,
+		client.SignatureSourceOnChain:   pb.SignatureSource_SIGNATURE_SOURCE_ON_CHAIN,
+		client.SignatureSourceSync:      pb.SignatureSource_SIGNATURE_SOURCE_SYNC,
+	}
+)
+
+// signatureTypeFromPB returns the type a request asked for, or "" if it was left unspecified
+func signatureTypeFromPB(typ pb.SignatureType) (client.SignatureType, error) {
+	if typ == pb.SignatureType_SIGNATURE_TYPE_UNSPECIFIED {
+		return "", nil
+	}
+	for k, v := range pbSignatureTypes {
+		if v == typ {
+			return k, nil
+		}
+	}
+	return "", status.Error(codes.InvalidArgument, "invalid signature type")
+}
+
+func signatureToPB(sig *client.SignatureData) *pb.Signature {
+	result := &pb.Signature{
+		Name:      sig.Name,
+		Filtered:  sig.Filtered,
+		Score:     sig.Score,
+		Source:    pbSignatureSources[sig.Source],
+		Submitter: sig.Submitter,
+	}
+	if sig.CreatedAt != nil {
+		result.CreatedAt = timestamppb.New(*sig.CreatedAt)
+	}
+	return result
+}
+
+// grpcServer serves the same data as the json api, for clients which would rather have a typed schema
+type grpcServer struct {
+	pb.UnimplementedSignatureDatabaseServer
+
+	s *Service
+}
+
+func (s *Service) newGrpcServer() *grpc.Server {
+	server := grpc.NewServer(
+		grpc.MaxRecvMsgSize(int(s.config.MaxBodySize)),
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+			defer s.observeGrpc(info.FullMethod, time.Now(), &err)
+
+			ctx, err = s.authorizeGrpc(ctx, info.FullMethod)
+			if err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+			defer s.observeGrpc(info.FullMethod, time.Now(), &err)
+
+			ctx, err := s.authorizeGrpc(stream.Context(), info.FullMethod)
+			if err != nil {
+				return err
+			}
+			return handler(srv, &authorizedStream{ServerStream: stream, ctx: ctx})
+		}),
+	)
+	pb.RegisterSignatureDatabaseServer(server, &grpcServer{s: s})
+	return server
+}
+
+func (s *Service) startGrpcServer() error {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", s.config.GrpcPort))
+	if err != nil {
+		return fmt.Errorf("failed to listen for grpc: %w", err)
+	}
+
+	go func() {
+		s.errs <- fmt.Errorf("failed to serve grpc: %w", s.newGrpcServer().Serve(listener))
+	}()
+
+	return nil
+}
+
+// observeGrpc records a call in the same request metrics as http, labelled by its method and status code
+func (s *Service) observeGrpc(method string, start time.Time, err *error) {
+	s.monitor.Observe("grpc", method, status.Code(*err).String(), time.Since(start))
+}
+
+// authorizedStream carries the context with the authenticated key through to streaming handlers
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (a *authorizedStream) Context() context.Context {
+	return a.ctx
+}
+
+// authorizeGrpc applies the same rate limits and authentication as route does for http, in the same order: calls are
+// charged to their address before their key is looked at, and then also to their key. Keys are only accepted as
+// bearer tokens, since there's no canonical request to sign
+func (s *Service) authorizeGrpc(ctx context.Context, method string) (context.Context, error) {
+	role, ok := grpcRoles[method]
+	if !ok {
+		role = auth.RoleAdmin
+	}
+
+	if err := s.allowGrpc(ctx, ratelimit.IPKey(grpcClientIP(ctx, s.proxies)), s.ipLimit()); err != nil {
+		return nil, err
+	}
+
+	var key *auth.Key
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get("authorization"); len(values) > 0 && strings.HasPrefix(values[0], "Bearer ") {
+		var err error
+		key, err = s.auth.AuthenticateToken(ctx, strings.TrimPrefix(values[0], "Bearer "))
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+	}
+
+	ctx, err := s.auth.Authorize(ctx, key, role)
+	if errors.Is(err, auth.ErrForbidden) {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	} else if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	if key := auth.KeyFromContext(ctx); key != nil {
+		if err := s.allowGrpc(ctx, "key:"+key.ID, s.keyLimit()); err != nil {
+			return nil, err
+		}
+	}
+
+	return ctx, nil
+}
+
+// allowGrpc charges a call to the bucket like allow does for http, failing with ResourceExhausted if it's over the limit
+func (s *Service) allowGrpc(ctx context.Context, bucket string, limit ratelimit.Limit) error {
+	if s.limiter == nil {
+		return nil
+	}
+
+	ok, wait, err := s.limiter.Allow(ctx, bucket, limit)
+	if err != nil {
+		log.WithError(err).Warnf("failed to check rate limit")
+	} else if !ok {
+		_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(int(math.Ceil(wait.Seconds())))))
+		return status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+	return nil
+}
+
+// grpcClientIP works out the address of the client the same way as for http, from the forwarding headers set by
+// trusted proxies, which arrive as metadata
+func grpcClientIP(ctx context.Context, proxies core.TrustedProxies) string {
+	r := &http.Request{Header: make(http.Header)}
+	if p, ok := peer.FromContext(ctx); ok {
+		r.RemoteAddr = p.Addr.String()
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, header := range []string{"CF-Connecting-IP", "X-Forwarded-For", "X-Real-IP"} {
+		for _, value := range md.Get(header) {
+			r.Header.Add(header, value)
+		}
+	}
+	return proxies.ClientIP(r)
+}
+
+func (s *Service) grpcFields(ctx context.Context) log.Fields {
+	fields := log.Fields{
+		"ip": grpcClientIP(ctx, s.proxies),
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	if ua := md.Get("user-agent"); len(ua) > 0 {
+		fields["ua"] = ua[0]
+	}
+	return fields
+}
+
+// grpcFail logs unexpected errors like fail does, without leaking their details to the client
+func grpcFail(err error, msg string) error {
+	log.WithError(err).Errorf(msg)
+	return status.Error(codes.Internal, msg)
+}
+
+func (g *grpcServer) Lookup(ctx context.Context, req *pb.LookupRequest) (*pb.LookupResponse, error) {
+	hashes := map[client.SignatureType][]string{
+		client.SignatureTypeFunction: req.Functions,
+		client.SignatureTypeEvent:    req.Events,
+		client.SignatureTypeError:    req.Errors,
+	}
+
+	count := 0
+	for _, values := range hashes {
+		for i, hash := range values {
+			if _, err := hexutil.Decode(hash); err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid hash: %s", hash)
+			}
+			values[i] = strings.ToLower(hash)
+		}
+		count += len(values)
+	}
+	if err := g.s.checkLookupSize(count); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	response := client.NewSignatureResponse()
+	for typ, values := range hashes {
+		if len(values) == 0 {
+			continue
+		}
+
+		var err error
+		response[typ], err = g.s.lookupSignatures(typ, values)
+		if err != nil {
+			return nil, grpcFail(err, "failed to load signatures")
+		}
+	}
+
+	g.s.filterResponse(response, !req.Unfiltered)
+	if !req.Metadata {
+		stripMetadata(response)
+	}
+	g.s.logSignatureResponse(g.s.grpcFields(ctx), response)
+
+	result := &pb.LookupResponse{}
+	for _, typ := range client.SignatureTypes() {
+		for _, hash := range hashes[typ] {
+			entry := &pb.LookupResult{
+				Type: pbSignatureTypes[typ],
+				Hash: hash,
+			}
+			for _, sig := range response[typ][hash] {
+				entry.Signatures = append(entry.Signatures, signatureToPB(sig))
+			}
+			result.Results = append(result.Results, entry)
+		}
+	}
+	return result, nil
+}
+
+func (g *grpcServer) Search(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error) {
+	typ, err := signatureTypeFromPB(req.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := int(req.Limit)
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	if limit < 0 || limit > maxSearchLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "limit must be between 1 and %d", maxSearchLimit)
+	}
+
+	response, err := g.s.db.QuerySignatures(&database.SearchFilter{
+		Query: req.Query,
+		Name:  req.Name,
+		Args:  req.Args,
+		Param: req.Param,
+		Type:  typ,
+	}, req.Cursor, limit)
+	if err != nil {
+		if errors.Is(err, database.ErrInvalidCursor) || errors.Is(err, database.ErrInvalidSearch) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, grpcFail(err, "failed to query signatures")
+	}
+
+	g.s.filterSearchResponse(response, !req.Unfiltered)
+	g.s.logSearchResponse(g.s.grpcFields(ctx), response)
+
+	result := &pb.SearchResponse{
+		NextCursor: response.NextCursor,
+	}
+	for _, typ := range client.SignatureTypes() {
+		for _, v := range response.Results[typ] {
+			result.Results = append(result.Results, &pb.SearchResult{
+				Type:      pbSignatureTypes[typ],
+				Hash:      v.Hash,
+				Signature: signatureToPB(v.SignatureData),
+			})
+		}
+	}
+	return result, nil
+}
+
+func (g *grpcServer) Import(ctx context.Context, req *pb.ImportRequest) (*pb.ImportResponse, error) {
+	meta, err := newImportMetadata(ctx, req.Submitter, client.SignatureSourceImport)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	importReq := client.ImportRequest{
+		client.SignatureTypeFunction: req.Functions,
+		client.SignatureTypeEvent:    req.Events,
+		client.SignatureTypeError:    req.Errors,
+	}
+	if err := g.s.checkImportSize(importReq); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	res, err := g.s.importRaw(importReq, meta)
+	if err != nil {
+		return nil, grpcFail(err, "failed to import")
+	}
+
+	g.s.logImportResponse(g.s.grpcFields(ctx), res)
+
+	result := &pb.ImportResponse{}
+	for _, typ := range client.SignatureTypes() {
+		details := res[typ]
+		result.Results = append(result.Results, &pb.ImportResult{
+			Type:       pbSignatureTypes[typ],
+			Imported:   details.Imported,
+			Duplicated: details.Duplicated,
+			Invalid:    details.Invalid,
+			Rewritten:  details.Rewritten,
+		})
+	}
+	return result, nil
+}
+
+func (g *grpcServer) Stats(ctx context.Context, req *pb.StatsRequest) (*pb.StatsResponse, error) {
+	counts := make(map[client.SignatureType]int64)
+	for _, typ := range client.SignatureTypes() {
+		count, err := g.s.db.CountSignatures(typ)
+		if err != nil {
+			return nil, grpcFail(err, "failed to count signatures")
+		}
+		counts[typ] = int64(count)
+	}
+
+	return &pb.StatsResponse{
+		Functions: counts[client.SignatureTypeFunction],
+		Events:    counts[client.SignatureTypeEvent],
+		Errors:    counts[client.SignatureTypeError],
+	}, nil
+}
+
+// Export serves full exports from the periodic snapshot like http does, ordered by hash, while incremental exports
+// come from the database ordered by id so that they can be resumed from the last id received. Without a since_id or
+// since the export is a full one. The version is sent as x-export-version header metadata
+func (g *grpcServer) Export(req *pb.ExportRequest, stream pb.SignatureDatabase_ExportServer) error {
+	typ, err := signatureTypeFromPB(req.Type)
+	if err != nil {
+		return err
+	}
+	types := client.SignatureTypes()
+	if typ != "" {
+		types = []client.SignatureType{typ}
+	}
+
+	if req.SinceId < 0 {
+		return status.Error(codes.InvalidArgument, "since_id must be a non-negative integer")
+	}
+	since := database.ExportSince{ID: req.SinceId}
+	if req.Since != nil {
+		since.Time = req.Since.AsTime()
+	}
+
+	var (
+		version  int64
+		snapshot *exportSnapshot
+	)
+	if since.ID == 0 && since.Time.IsZero() {
+		g.s.dataExportLock.Lock()
+		snapshot = g.s.dataExport
+		g.s.dataExportLock.Unlock()
+
+		if snapshot == nil {
+			return status.Error(codes.Unavailable, "export is not ready yet")
+		}
+		version = snapshot.version
+	} else if version = g.s.currentExportVersion(); version < 0 {
+		return status.Error(codes.Unavailable, "export is not ready yet")
+	}
+	if err := stream.SendHeader(metadata.Pairs("x-export-version", strconv.FormatInt(version, 10))); err != nil {
+		return err
+	}
+
+	fields := g.s.grpcFields(stream.Context())
+	fields["types"] = types
+	fields["since_id"] = since.ID
+	fields["since"] = since.Time
+	fields["version"] = version
+	log.WithFields(fields).Infof("serving export")
+
+	send := func(sig *client.ExportedSignature) error {
+		return stream.Send(&pb.ExportedSignature{
+			Id:      sig.ID,
+			Type:    pbSignatureTypes[sig.Type],
+			Hash:    sig.Hash,
+			Name:    sig.Name,
+			Removed: sig.Removed,
+		})
+	}
+	for _, typ := range types {
+		if snapshot != nil {
+			err = streamSnapshot(snapshot, typ, send)
+		} else {
+			err = g.s.db.ExportChanges(typ, version, since, send)
+		}
+		if err != nil {
+			if stream.Context().Err() != nil {
+				return status.FromContextError(stream.Context().Err()).Err()
+			}
+			return grpcFail(err, "failed to stream export")
+		}
+	}
+
+	return nil
+}
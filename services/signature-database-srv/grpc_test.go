@@ -0,0 +1,148 @@
+package signature_database_srv
+
+import (
+	"context"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/auth"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/monitoring"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/ratelimit"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/client"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/pb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+func newTestGrpcClient(t *testing.T, s *Service) pb.SignatureDatabaseClient {
+	listener := bufconn.Listen(1024 * 1024)
+	server := s.newGrpcServer()
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewSignatureDatabaseClient(conn)
+}
+
+func Test_GrpcAuthorization(t *testing.T) {
+	_, readOnly, err := auth.GenerateKey("reader", auth.RoleReadOnly)
+	assert.NoError(t, err)
+	keys, err := auth.ParseStaticKeys([]string{"reader:read_only:" + readOnly})
+	assert.NoError(t, err)
+
+	s := &Service{
+		config: &Config{MaxBodySize: 1024 * 1024, MaxLookupHashes: 2},
+		auth: auth.New(
+			auth.WithKeyStore(keys),
+			auth.WithAnonymousRole(auth.RoleNone),
+		),
+		canonicalSignatures: make(map[string]string),
+		monitor:             monitoring.New("signature-database-srv"),
+		metrics:             newServiceMetrics(),
+	}
+	c := newTestGrpcClient(t, s)
+
+	withToken := func(token string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	}
+
+	_, err = c.Lookup(context.Background(), &pb.LookupRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = c.Lookup(withToken("nope.nope"), &pb.LookupRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	resp, err := c.Lookup(withToken(readOnly), &pb.LookupRequest{})
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Results)
+
+	_, err = c.Lookup(withToken(readOnly), &pb.LookupRequest{Functions: []string{"0xa9059cbb", "0x095ea7b3", "0x23b872dd"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = c.Lookup(withToken(readOnly), &pb.LookupRequest{Functions: []string{"transfer"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = c.Import(withToken(readOnly), &pb.ImportRequest{Functions: []string{"transfer(address,uint256)"}})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = c.Search(withToken(readOnly), &pb.SearchRequest{Query: "transfer*", Limit: 5000})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	stream, err := c.Export(withToken(readOnly), &pb.ExportRequest{SinceId: -1})
+	assert.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func Test_GrpcRateLimit(t *testing.T) {
+	s := &Service{
+		config: &Config{MaxBodySize: 1024 * 1024, IPRateLimit: 1, IPRateBurst: 1},
+		auth: auth.New(
+			auth.WithAnonymousRole(auth.RoleReadOnly),
+		),
+		limiter: ratelimit.NewMemoryLimiter(),
+		monitor: monitoring.New("signature-database-srv"),
+	}
+	c := newTestGrpcClient(t, s)
+
+	// bogus keys are charged to the address before they're turned away, like over http
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer unknown.secret")
+	_, err := c.Stats(ctx, &pb.StatsRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = c.Stats(ctx, &pb.StatsRequest{})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	_, err = c.Stats(context.Background(), &pb.StatsRequest{})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func Test_GrpcExportSnapshot(t *testing.T) {
+	snapshot := &exportSnapshot{dir: t.TempDir(), version: 3, time: time.Now()}
+	assert.NoError(t, os.WriteFile(snapshot.path(client.SignatureTypeFunction), []byte("3,0x095ea7b3,approve(address,uint256)\n1,0xa9059cbb,transfer(address,uint256)\n"), 0644))
+
+	s := &Service{
+		config:  &Config{MaxBodySize: 1024 * 1024},
+		auth:    auth.New(auth.WithAnonymousRole(auth.RoleReadOnly)),
+		monitor: monitoring.New("signature-database-srv"),
+	}
+	c := newTestGrpcClient(t, s)
+
+	stream, err := c.Export(context.Background(), &pb.ExportRequest{Type: pb.SignatureType_SIGNATURE_TYPE_FUNCTION})
+	assert.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	s.dataExportLock.Lock()
+	s.dataExport = snapshot
+	s.dataExportLock.Unlock()
+	stream, err = c.Export(context.Background(), &pb.ExportRequest{Type: pb.SignatureType_SIGNATURE_TYPE_FUNCTION})
+	assert.NoError(t, err)
+	var ids []int64
+	for {
+		sig, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		ids = append(ids, sig.Id)
+	}
+	assert.Equal(t, []int64{3, 1}, ids)
+
+	header, err := stream.Header()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"3"}, header.Get("x-export-version"))
+}
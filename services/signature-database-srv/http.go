@@ -1,16 +1,23 @@
 package signature_database_srv
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/auth"
 	"github.com/openchainxyz/openchainxyz-monorepo/internal/core"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/solidity"
 	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/client"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/database"
 	log "github.com/sirupsen/logrus"
-	"io"
+	"net"
 	"net/http"
-	"os"
+	"strconv"
 	"strings"
 )
 
@@ -41,13 +48,25 @@ func (s *Service) serveLookup(w http.ResponseWriter, r *http.Request) {
 
 	params := r.URL.Query()
 	shouldFilter := !params.Has("filter") || params.Get("filter") != "false"
+	includeMetadata := params.Get("metadata") == "true"
 
+	hashes := make(map[client.SignatureType][]string)
+	count := 0
 	for _, typ := range client.SignatureTypes() {
 		data := params.Get(string(typ))
 		if len(data) == 0 {
 			continue
 		}
-		response[typ], err = s.db.LoadSignatures(typ, strings.Split(data, ","))
+		hashes[typ] = strings.Split(data, ",")
+		count += len(hashes[typ])
+	}
+	if err := s.checkLookupSize(count); err != nil {
+		fail(w, http.StatusBadRequest, err, err.Error())
+		return
+	}
+
+	for typ, data := range hashes {
+		response[typ], err = s.lookupSignatures(typ, data)
 		if err != nil {
 			fail(w, http.StatusInternalServerError, err, "failed to load signatures")
 			return
@@ -55,24 +74,98 @@ func (s *Service) serveLookup(w http.ResponseWriter, r *http.Request) {
 	}
 
 	s.filterResponse(response, shouldFilter)
-	s.logSignatureResponse(r, response)
+	if !includeMetadata {
+		stripMetadata(response)
+	}
+	s.logSignatureResponse(requestFields(r), response)
 
 	succeed(w, response)
 }
 
-func (s *Service) serveSearch(w http.ResponseWriter, r *http.Request) {
+func stripMetadata(response client.SignatureResponse) {
+	for _, sigs := range response {
+		for _, values := range sigs {
+			for _, value := range values {
+				value.CreatedAt = nil
+				value.Source = ""
+				value.Submitter = ""
+			}
+		}
+	}
+}
+
+const (
+	defaultSearchLimit = 100
+	maxSearchLimit     = 1000
+)
+
+// search runs the search described by the query parameters, writing the failure if there is one
+func (s *Service) search(w http.ResponseWriter, r *http.Request, paginated bool) (*client.SearchResponse, bool) {
 	params := r.URL.Query()
-	query := params.Get("query")
-	shouldFilter := !params.Has("filter") || params.Get("filter") != "false"
+	filter := &database.SearchFilter{
+		Query: params.Get("query"),
+		Name:  params.Get("name"),
+		Args:  params.Get("args"),
+		Param: params.Get("param"),
+		Type:  client.SignatureType(params.Get("type")),
+	}
 
-	response, err := s.db.QuerySignatures(query)
+	var cursor string
+	limit := defaultSearchLimit
+	if paginated {
+		cursor = params.Get("cursor")
+
+		if params.Has("limit") {
+			var err error
+			limit, err = strconv.Atoi(params.Get("limit"))
+			if err != nil || limit <= 0 || limit > maxSearchLimit {
+				fail(w, http.StatusBadRequest, err, fmt.Sprintf("limit must be between 1 and %d", maxSearchLimit))
+				return nil, false
+			}
+		}
+	}
+
+	response, err := s.db.QuerySignatures(filter, cursor, limit)
 	if err != nil {
+		if errors.Is(err, database.ErrInvalidCursor) || errors.Is(err, database.ErrInvalidSearch) {
+			fail(w, http.StatusBadRequest, err, err.Error())
+			return nil, false
+		}
 		fail(w, http.StatusInternalServerError, err, "failed to query signatures")
+		return nil, false
+	}
+
+	return response, true
+}
+
+// serveSearchV1 keeps the original shape of /v1/search, with results grouped by hash and only the first page
+func (s *Service) serveSearchV1(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	shouldFilter := !params.Has("filter") || params.Get("filter") != "false"
+
+	response, ok := s.search(w, r, false)
+	if !ok {
 		return
 	}
 
-	s.filterResponse(response, shouldFilter)
-	s.logSignatureResponse(r, response)
+	grouped := groupSearchResults(response)
+	s.filterResponse(grouped, shouldFilter)
+	s.logSignatureResponse(requestFields(r), grouped)
+
+	succeed(w, grouped)
+}
+
+func (s *Service) serveSearch(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	shouldFilter := !params.Has("filter") || params.Get("filter") != "false"
+
+	response, ok := s.search(w, r, true)
+	if !ok {
+		return
+	}
+
+	s.filterSearchResponse(response, shouldFilter)
+	s.logSearchResponse(requestFields(r), response)
 
 	succeed(w, response)
 }
@@ -85,22 +178,182 @@ func (s *Service) serveImport(w http.ResponseWriter, r *http.Request) {
 	)
 
 	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
-		fail(w, http.StatusBadRequest, err, "failed to decode body")
+		failDecode(w, err)
+		return
+	}
+
+	meta, err := importMetadata(r, client.SignatureSourceImport)
+	if err != nil {
+		fail(w, http.StatusBadRequest, err, err.Error())
+		return
+	}
+
+	if err := s.checkImportSize(req); err != nil {
+		fail(w, http.StatusRequestEntityTooLarge, err, err.Error())
+		return
+	}
+
+	res, err = s.importRaw(req, meta)
+
+	if err != nil {
+		fail(w, http.StatusInternalServerError, err, "failed to import")
+		return
+	}
+
+	s.logImportResponse(requestFields(r), res)
+
+	succeed(w, res)
+}
+
+func (s *Service) serveObserve(w http.ResponseWriter, r *http.Request) {
+	var req client.ImportRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		failDecode(w, err)
+		return
+	}
+
+	meta, err := importMetadata(r, client.SignatureSourceOnChain)
+	if err != nil {
+		fail(w, http.StatusBadRequest, err, err.Error())
+		return
+	}
+
+	if err := s.checkImportSize(req); err != nil {
+		fail(w, http.StatusRequestEntityTooLarge, err, err.Error())
+		return
+	}
+
+	res, err := s.importRaw(req, meta)
+	if err != nil {
+		fail(w, http.StatusInternalServerError, err, "failed to import")
+		return
+	}
+
+	s.logImportResponse(requestFields(r), res)
+
+	succeed(w, res)
+}
+
+func (s *Service) serveImportABI(w http.ResponseWriter, r *http.Request) {
+	var req client.ImportABIRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		failDecode(w, err)
+		return
+	}
+
+	meta, err := importMetadata(r, client.SignatureSourceABI)
+	if err != nil {
+		fail(w, http.StatusBadRequest, err, err.Error())
+		return
+	}
+
+	importReq, err := s.abiToImportRequest(req.ABI)
+	if err != nil {
+		fail(w, http.StatusBadRequest, err, "failed to parse abi")
+		return
+	}
+
+	if err := s.checkImportSize(importReq); err != nil {
+		fail(w, http.StatusRequestEntityTooLarge, err, err.Error())
+		return
+	}
+
+	res, err := s.importRaw(importReq, meta)
+	if err != nil {
+		fail(w, http.StatusInternalServerError, err, "failed to import")
+		return
+	}
+
+	s.logImportResponse(requestFields(r), res)
+
+	succeed(w, res)
+}
+
+func (s *Service) serveImportSource(w http.ResponseWriter, r *http.Request) {
+	var req client.ImportSourceRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		failDecode(w, err)
+		return
+	}
+
+	meta, err := importMetadata(r, client.SignatureSourceCompiled)
+	if err != nil {
+		fail(w, http.StatusBadRequest, err, err.Error())
+		return
+	}
+
+	if err := s.checkSourceSize(&req); err != nil {
+		fail(w, http.StatusRequestEntityTooLarge, err, err.Error())
+		return
+	}
+
+	abis, err := s.compileSource(r.Context(), &req)
+	if err != nil {
+		fail(w, http.StatusBadRequest, err, "failed to compile source")
+		return
+	}
+
+	importReq, err := s.abiToImportRequest(abis)
+	if err != nil {
+		fail(w, http.StatusInternalServerError, err, "failed to parse abi")
 		return
 	}
 
-	res, err = s.importRaw(req)
+	if err := s.checkImportSize(importReq); err != nil {
+		fail(w, http.StatusRequestEntityTooLarge, err, err.Error())
+		return
+	}
 
+	res, err := s.importRaw(importReq, meta)
 	if err != nil {
 		fail(w, http.StatusInternalServerError, err, "failed to import")
 		return
 	}
 
-	s.logImportResponse(r, res)
+	s.logImportResponse(requestFields(r), res)
 
 	succeed(w, res)
 }
 
+const (
+	maxSubmitterLength       = 64
+	anonymousSubmitterPrefix = "anon:"
+)
+
+// importMetadata builds the metadata to record for signatures imported by this request. The submitter is the name of
+// the key used to authenticate, or else a free-form label chosen by the caller, such as the name of their pipeline,
+// prefixed with "anon:" so that it can't pass for a key.
+func importMetadata(r *http.Request, source client.SignatureSource) (*database.SignatureMetadata, error) {
+	return newImportMetadata(r.Context(), r.URL.Query().Get("submitter"), source)
+}
+
+func newImportMetadata(ctx context.Context, submitter string, source client.SignatureSource) (*database.SignatureMetadata, error) {
+	if len(submitter) > maxSubmitterLength {
+		return nil, fmt.Errorf("submitter must be at most %d characters", maxSubmitterLength)
+	}
+
+	// authenticated submitters can't claim to be someone else
+	if key := auth.KeyFromContext(ctx); key != nil {
+		submitter = key.Name
+	} else if submitter != "" {
+		submitter = anonymousSubmitterPrefix + submitter
+	}
+
+	// anyone can compile source declaring whatever they like, so source only counts towards the score when it comes from
+	// an observer key, such as a pipeline importing verified contracts. Nothing which decides whether a collision is
+	// suspicious can be minted anonymously
+	key := auth.KeyFromContext(ctx)
+	verified := source == client.SignatureSourceCompiled && key != nil && key.Role.Includes(auth.RoleObserver)
+	return &database.SignatureMetadata{
+		Source:    source,
+		Submitter: submitter,
+		Observed:  key != nil && (source == client.SignatureSourceOnChain || verified),
+	}, nil
+}
+
 func (s *Service) filterResponse(response client.SignatureResponse, shouldFilter bool) {
 	s.canonicalSignaturesLock.RLock()
 
@@ -129,11 +382,43 @@ func (s *Service) filterResponse(response client.SignatureResponse, shouldFilter
 	}
 }
 
-func (s *Service) logSignatureResponse(r *http.Request, response client.SignatureResponse) {
-	fields := log.Fields{
+// groupSearchResults groups search results by hash, sharing their signature data
+func groupSearchResults(response *client.SearchResponse) client.SignatureResponse {
+	grouped := client.NewSignatureResponse()
+	for typ, results := range response.Results {
+		for _, result := range results {
+			grouped[typ][result.Hash] = append(grouped[typ][result.Hash], result.SignatureData)
+		}
+	}
+	return grouped
+}
+
+// filterSearchResponse marks search results with filterResponse, keeping them in ranked order
+func (s *Service) filterSearchResponse(response *client.SearchResponse, shouldFilter bool) {
+	s.filterResponse(groupSearchResults(response), false)
+
+	if shouldFilter {
+		newResults := []*client.SearchResult{}
+
+		for _, result := range response.Results[client.SignatureTypeFunction] {
+			if !result.Filtered {
+				newResults = append(newResults, result)
+			}
+		}
+
+		response.Results[client.SignatureTypeFunction] = newResults
+	}
+}
+
+// requestFields are the fields every request is logged with
+func requestFields(r *http.Request) log.Fields {
+	return log.Fields{
 		"ip": core.GetRemoteIP(r),
 		"ua": core.GetUserAgent(r),
 	}
+}
+
+func (s *Service) logSignatureResponse(fields log.Fields, response client.SignatureResponse) {
 	for typ, b := range response {
 		var result []string
 		for k, v := range b {
@@ -148,11 +433,18 @@ func (s *Service) logSignatureResponse(r *http.Request, response client.Signatur
 	log.WithFields(fields).Infof("queried signatures")
 }
 
-func (s *Service) logImportResponse(r *http.Request, res client.ImportResponse) {
-	fields := log.Fields{
-		"ip": core.GetRemoteIP(r),
-		"ua": core.GetUserAgent(r),
+func (s *Service) logSearchResponse(fields log.Fields, response *client.SearchResponse) {
+	for typ, results := range response.Results {
+		var result []string
+		for _, v := range results {
+			result = append(result, fmt.Sprintf("%s=%s", v.Hash, v.Name))
+		}
+		fields[string(typ)] = strings.Join(result, ";")
 	}
+	log.WithFields(fields).Infof("searched signatures")
+}
+
+func (s *Service) logImportResponse(fields log.Fields, res client.ImportResponse) {
 	for _, typ := range client.SignatureTypes() {
 		var imported []string
 		var duplicated []string
@@ -181,6 +473,12 @@ func (s *Service) serveStats(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	resp.Audit, err = s.latestAuditReport()
+	if err != nil {
+		fail(w, http.StatusInternalServerError, err, "failed to load audit report")
+		return
+	}
+
 	succeed(w, resp)
 }
 
@@ -193,67 +491,192 @@ func (s *Service) serveRefreshCanonicalSignatures(w http.ResponseWriter, r *http
 	succeed(w, nil)
 }
 
-func (s *Service) serveExport(w http.ResponseWriter, r *http.Request) {
-	s.dataExportLock.Lock()
-	lastPath := s.dataExportPath
-	s.dataExportLock.Unlock()
+func (s *Service) serveListCanonicalSignatures(w http.ResponseWriter, r *http.Request) {
+	sigs, err := s.db.LoadCanonicalSignatures()
+	if err != nil {
+		fail(w, http.StatusInternalServerError, err, "failed to load canonical signatures")
+		return
+	}
+
+	succeed(w, sigs)
+}
 
-	if lastPath == "" {
-		fail(w, http.StatusInternalServerError, nil, "export is not ready yet")
+func (s *Service) serveAddCanonicalSignature(w http.ResponseWriter, r *http.Request) {
+	var req client.CanonicalSignature
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		failDecode(w, err)
 		return
 	}
 
-	f, err := os.Open(lastPath)
+	if !solidity.VerifySignature(req.Signature) {
+		fail(w, http.StatusBadRequest, nil, "invalid signature")
+		return
+	}
+
+	hash := hexutil.Encode(crypto.Keccak256([]byte(req.Signature))[:4])
+	if req.Hash != "" && strings.ToLower(req.Hash) != hash {
+		fail(w, http.StatusBadRequest, nil, fmt.Sprintf("signature hashes to %s", hash))
+		return
+	}
+	req.Hash = hash
+
+	if err := s.db.SaveCanonicalSignature(&req); err != nil {
+		fail(w, http.StatusInternalServerError, err, "failed to save canonical signature")
+		return
+	}
+
+	if err := s.reloadCanonicalSignatures(); err != nil {
+		fail(w, http.StatusInternalServerError, err, "failed to reload canonical signatures")
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"ip":        core.GetRemoteIP(r),
+		"hash":      req.Hash,
+		"signature": req.Signature,
+	}).Infof("added canonical signature")
+
+	succeed(w, nil)
+}
+
+func (s *Service) serveDeleteCanonicalSignature(w http.ResponseWriter, r *http.Request) {
+	hash := strings.ToLower(mux.Vars(r)["hash"])
+
+	deleted, err := s.db.DeleteCanonicalSignature(hash)
 	if err != nil {
-		fail(w, http.StatusInternalServerError, err, "failed to open file")
+		fail(w, http.StatusBadRequest, err, "failed to delete canonical signature")
+		return
+	}
+	if !deleted {
+		fail(w, http.StatusNotFound, nil, "canonical signature not found")
 		return
 	}
-	defer f.Close()
 
-	stat, err := f.Stat()
+	if err := s.reloadCanonicalSignatures(); err != nil {
+		fail(w, http.StatusInternalServerError, err, "failed to reload canonical signatures")
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"ip":   core.GetRemoteIP(r),
+		"hash": hash,
+	}).Infof("deleted canonical signature")
+
+	succeed(w, nil)
+}
+
+func (s *Service) serveListKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := s.keys.ListKeys(r.Context())
 	if err != nil {
-		fail(w, http.StatusInternalServerError, err, "failed to stat file")
+		fail(w, http.StatusInternalServerError, err, "failed to list keys")
 		return
 	}
 
-	fields := log.Fields{
-		"ip": core.GetRemoteIP(r),
-		"ua": core.GetUserAgent(r),
+	succeed(w, keys)
+}
+
+func (s *Service) serveCreateKey(w http.ResponseWriter, r *http.Request) {
+	var req client.CreateKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		failDecode(w, err)
+		return
 	}
-	log.WithFields(fields).Infof("served export")
 
-	w.Header().Set("Content-Disposition", `attachment; filename="export.txt"`)
-	w.Header().Set("Content-Type", "text/plain")
-	w.Header().Set("Content-Length", fmt.Sprintf("%d", stat.Size()))
-	w.WriteHeader(http.StatusOK)
+	role := auth.Role(req.Role)
+	if req.Name == "" || !role.Valid() || role == auth.RoleNone {
+		fail(w, http.StatusBadRequest, nil, "a name and a valid role are required")
+		return
+	}
 
-	if _, err := io.Copy(w, f); err != nil {
-		fail(w, http.StatusInternalServerError, err, "failed to copy file")
+	key, token, err := s.keys.CreateKey(r.Context(), req.Name, role)
+	if err != nil {
+		fail(w, http.StatusInternalServerError, err, "failed to create key")
 		return
 	}
 
-	return
+	log.WithFields(log.Fields{
+		"ip":   core.GetRemoteIP(r),
+		"by":   auth.KeyName(r.Context()),
+		"id":   key.ID,
+		"name": key.Name,
+		"role": key.Role,
+	}).Infof("created key")
+
+	succeed(w, &client.CreateKeyResponse{
+		ID:    key.ID,
+		Token: token,
+	})
+}
+
+func (s *Service) serveRevokeKey(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	revoked, err := s.keys.RevokeKey(r.Context(), id)
+	if err != nil {
+		fail(w, http.StatusInternalServerError, err, "failed to revoke key")
+		return
+	}
+	if !revoked {
+		fail(w, http.StatusNotFound, nil, "key not found")
+		return
+	}
 
+	log.WithFields(log.Fields{
+		"ip": core.GetRemoteIP(r),
+		"by": auth.KeyName(r.Context()),
+		"id": id,
+	}).Infof("revoked key")
+
+	succeed(w, nil)
 }
 
-func (s *Service) startServer() {
+func (s *Service) startServer() error {
 	m := mux.NewRouter()
-	m.HandleFunc("/v1/lookup", s.serveLookup).Methods("GET")
-	m.HandleFunc("/v1/search", s.serveSearch).Methods("GET")
-	m.HandleFunc("/v1/import", s.serveImport).Methods("POST")
-	m.HandleFunc("/v1/stats", s.serveStats).Methods("GET")
-	m.HandleFunc("/v1/export", s.serveExport).Methods("GET")
-	m.HandleFunc("/v1/refresh_canonical_signatures", s.serveRefreshCanonicalSignatures).Methods("POST")
+	s.monitor.Instrument(m)
+	m.HandleFunc("/v1/lookup", s.route(auth.RoleReadOnly, s.serveLookup)).Methods("GET")
+	m.HandleFunc("/v1/search", s.route(auth.RoleReadOnly, s.serveSearchV1)).Methods("GET")
+	m.HandleFunc("/v2/search", s.route(auth.RoleReadOnly, s.serveSearch)).Methods("GET")
+	m.HandleFunc("/v1/import", s.route(auth.RoleImporter, s.serveImport)).Methods("POST")
+	m.HandleFunc("/v1/import/abi", s.route(auth.RoleImporter, s.serveImportABI)).Methods("POST")
+	m.HandleFunc("/v1/import/source", s.route(auth.RoleImporter, s.serveImportSource)).Methods("POST")
+	m.HandleFunc("/v1/observe", s.route(auth.RoleObserver, s.serveObserve)).Methods("POST")
+	m.HandleFunc("/v1/stats", s.route(auth.RoleReadOnly, s.serveStats)).Methods("GET")
+	m.HandleFunc("/v1/decode", s.route(auth.RoleReadOnly, s.serveDecode)).Methods("POST")
+	m.HandleFunc("/v1/collisions", s.route(auth.RoleReadOnly, s.serveCollisions)).Methods("GET")
+	m.HandleFunc("/v1/export", s.route(auth.RoleReadOnly, s.serveExport)).Methods("GET")
+	m.HandleFunc("/graphql", s.route(auth.RoleReadOnly, s.serveGraphQL)).Methods("POST")
+	m.HandleFunc("/v1/refresh_canonical_signatures", s.route(auth.RoleAdmin, s.serveRefreshCanonicalSignatures)).Methods("POST")
+	m.HandleFunc("/v1/canonical", s.route(auth.RoleAdmin, s.serveListCanonicalSignatures)).Methods("GET")
+	m.HandleFunc("/v1/canonical", s.route(auth.RoleAdmin, s.serveAddCanonicalSignature)).Methods("POST")
+	m.HandleFunc("/v1/canonical/{hash}", s.route(auth.RoleAdmin, s.serveDeleteCanonicalSignature)).Methods("DELETE")
+	m.HandleFunc("/v1/audit", s.route(auth.RoleAdmin, s.serveAudit)).Methods("POST")
+	m.HandleFunc("/v1/keys", s.route(auth.RoleAdmin, s.serveListKeys)).Methods("GET")
+	m.HandleFunc("/v1/keys", s.route(auth.RoleAdmin, s.serveCreateKey)).Methods("POST")
+	m.HandleFunc("/v1/keys/{id}", s.route(auth.RoleAdmin, s.serveRevokeKey)).Methods("DELETE")
 
 	cors := handlers.CORS(
-		handlers.AllowedMethods([]string{"OPTIONS", "HEAD", "GET", "POST"}),
-		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedHeaders([]string{"X-Requested-With", "Content-Type"}),
+		handlers.AllowedMethods([]string{"OPTIONS", "HEAD", "GET", "POST", "DELETE"}),
+		handlers.AllowedOrigins(s.config.CorsOrigins),
+		handlers.AllowedHeaders([]string{"X-Requested-With", "Content-Type", "Authorization", auth.HeaderKeyID, auth.HeaderTimestamp, auth.HeaderSignature}),
 	)(m)
 
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", s.config.HttpPort))
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	monitoringListener, err := net.Listen("tcp", fmt.Sprintf(":%d", s.config.MetricsPort))
+	if err != nil {
+		listener.Close()
+		return fmt.Errorf("failed to listen for monitoring: %w", err)
+	}
+
 	go func() {
-		if err := http.ListenAndServe(fmt.Sprintf(":%d", s.config.HttpPort), cors); err != nil && err != http.ErrServerClosed {
-			log.WithError(err).Errorf("failed to listen and server")
-		}
+		s.errs <- fmt.Errorf("failed to serve: %w", http.Serve(listener, s.proxies.Handler(cors)))
+	}()
+
+	go func() {
+		s.errs <- fmt.Errorf("failed to serve monitoring: %w", http.Serve(monitoringListener, s.monitor.Handler()))
 	}()
+
+	return nil
 }
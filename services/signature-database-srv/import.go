@@ -1,20 +1,25 @@
 package signature_database_srv
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"github.com/bwmarrin/discordgo"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/compiler"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/notify"
 	"github.com/openchainxyz/openchainxyz-monorepo/internal/solidity"
 	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/client"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/database"
 	log "github.com/sirupsen/logrus"
+	"regexp"
 	"strings"
 )
 
-func (s *Service) importRaw(data client.ImportRequest) (client.ImportResponse, error) {
+func (s *Service) importRaw(data client.ImportRequest, meta *database.SignatureMetadata) (client.ImportResponse, error) {
 	response := client.NewImportResponse()
 
 	var err error
 	for _, typ := range client.SignatureTypes() {
-		response[typ], err = s.importRawType(typ, data[typ])
+		response[typ], err = s.importRawType(typ, data[typ], meta)
 		if err != nil {
 			return nil, fmt.Errorf("failed to save signatures to db: %w", err)
 		}
@@ -23,33 +28,175 @@ func (s *Service) importRaw(data client.ImportRequest) (client.ImportResponse, e
 	return response, nil
 }
 
-func (s *Service) importRawType(typ client.SignatureType, input []string) (*client.ImportResponseDetails, error) {
+func (s *Service) abiToImportRequest(abis []json.RawMessage) (client.ImportRequest, error) {
+	request := make(client.ImportRequest)
+
+	// contracts commonly share signatures through inheritance, so only keep the first copy of each
+	seen := make(map[string]struct{})
+	add := func(typ client.SignatureType, sigs []string) {
+		for _, sig := range sigs {
+			key := string(typ) + ":" + sig
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			request[typ] = append(request[typ], sig)
+		}
+	}
+
+	for idx, raw := range abis {
+		sigs, err := solidity.ExtractSignatures(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract signatures from abi %d: %w", idx, err)
+		}
+
+		add(client.SignatureTypeFunction, sigs.Functions)
+		add(client.SignatureTypeEvent, sigs.Events)
+		add(client.SignatureTypeError, sigs.Errors)
+	}
+
+	return request, nil
+}
+
+var isValidCompilerVersion = regexp.MustCompile(`^[0-9]+\.[0-9]+\.[0-9]+$`).MatchString
+
+// compileSource compiles the submitted source and returns the abi of every contract in it. Anyone holding an importer
+// key can pick the compiler, so the compile is bounded by CompileTimeout and can't read anything beyond what's submitted
+func (s *Service) compileSource(ctx context.Context, req *client.ImportSourceRequest) ([]json.RawMessage, error) {
+	if !isValidCompilerVersion(req.Version) {
+		return nil, fmt.Errorf("invalid compiler version: %s", req.Version)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.config.CompileTimeout)
+	defer cancel()
+
+	var contracts map[string]*compiler.Contract
+	switch req.Language {
+	case client.SourceLanguageSolidity:
+		c, err := compiler.NewSolidityCompiler(ctx, req.Version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create compiler: %w", err)
+		}
+
+		if len(req.Input) > 0 {
+			return compileStandardJSON(ctx, c, req.Input)
+		}
+
+		if contracts, err = c.CompileFromString(ctx, req.Source); err != nil {
+			return nil, fmt.Errorf("failed to compile: %w", err)
+		}
+	case client.SourceLanguageVyper:
+		if len(req.Input) > 0 {
+			return nil, fmt.Errorf("standard json input is only supported for solidity")
+		}
+
+		c, err := compiler.NewVyperCompiler(ctx, req.Version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create compiler: %w", err)
+		}
+
+		if contracts, err = c.CompileFromString(ctx, req.Source); err != nil {
+			return nil, fmt.Errorf("failed to compile: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported language: %s", req.Language)
+	}
+
+	var abis []json.RawMessage
+	for name, contract := range contracts {
+		b, err := json.Marshal(contract.Info.AbiDefinition)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal abi for %s: %w", name, err)
+		}
+		abis = append(abis, b)
+	}
+
+	return abis, nil
+}
+
+func compileStandardJSON(ctx context.Context, c *compiler.SolidityCompiler, raw json.RawMessage) ([]json.RawMessage, error) {
+	var input compiler.StandardJsonInput
+	if err := json.Unmarshal(raw, &input); err != nil {
+		return nil, fmt.Errorf("failed to decode standard json input: %w", err)
+	}
+	withABIOutputSelection(&input)
+
+	output, err := c.CompileFromStandardJSON(ctx, &input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile: %w", err)
+	}
+	for _, e := range output.Errors {
+		if e.Severity == "error" {
+			return nil, fmt.Errorf("failed to compile: %s", e.FormattedMessage)
+		}
+	}
+
+	var abis []json.RawMessage
+	for file, contracts := range output.Contracts {
+		for name, contract := range contracts {
+			b, err := json.Marshal(contract.ABI)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal abi for %s:%s: %w", file, name, err)
+			}
+			abis = append(abis, b)
+		}
+	}
+
+	return abis, nil
+}
+
+// withABIOutputSelection overrides the output selection of a standard json input so solc only emits the abi
+func withABIOutputSelection(input *compiler.StandardJsonInput) {
+	if input.Settings == nil {
+		input.Settings = make(map[string]any)
+	}
+	input.Settings["outputSelection"] = map[string]any{
+		"*": map[string]any{
+			"*": []string{"abi"},
+		},
+	}
+}
+
+func (s *Service) importRawType(typ client.SignatureType, input []string, meta *database.SignatureMetadata) (*client.ImportResponseDetails, error) {
 	var pending []string
 	var invalid []string
+	rewritten := make(map[string]string)
 	for _, text := range input {
 		if solidity.VerifySignature(text) {
 			pending = append(pending, text)
-		} else {
+			continue
+		}
+
+		// an event declaration submitted as a function is more likely a mistake than a signature we want
+		sig, kind, err := solidity.NormalizeSignature(text)
+		if err != nil || (kind != "" && kind != string(typ)) {
 			invalid = append(invalid, text)
+			continue
 		}
+		pending = append(pending, sig)
+		rewritten[text] = sig
 	}
 
-	resp, err := s.db.SaveSignatures(typ, pending)
+	resp, err := s.db.SaveSignatures(typ, pending, meta)
 	if err != nil {
 		return nil, err
 	}
 
-	if s.discord != nil {
-		if err := s.notifyDiscord(typ, resp); err != nil {
-			log.WithError(err).Errorf("failed to notify discord of import")
+	if len(resp.Imported) > 0 {
+		if err := s.checkImported(typ, resp); err != nil {
+			log.WithError(err).Errorf("failed to check imported signatures for duplicates")
 		}
 	}
 
 	resp.Invalid = invalid
+	resp.Rewritten = rewritten
+	s.recordImport(typ, resp)
 	return resp, nil
 }
 
-func (s *Service) notifyDiscord(typ client.SignatureType, resp *client.ImportResponseDetails) error {
+// checkImported looks for newly imported signatures which share their hash with others, recording them as collisions
+// and reporting them to the notifiers
+func (s *Service) checkImported(typ client.SignatureType, resp *client.ImportResponseDetails) error {
 	var imported []string
 	for _, hash := range resp.Imported {
 		imported = append(imported, hash)
@@ -57,10 +204,20 @@ func (s *Service) notifyDiscord(typ client.SignatureType, resp *client.ImportRes
 
 	sigs, err := s.db.LoadSignatures(typ, imported)
 	if err != nil {
+		return fmt.Errorf("failed to load signatures: %w", err)
+	}
+
+	if err := s.checkCollisions(typ, sigs, false); err != nil {
 		return err
 	}
 
+	s.notifyDuplicates(typ, sigs)
+	return nil
+}
+
+func (s *Service) notifyDuplicates(typ client.SignatureType, sigs map[string][]*client.SignatureData) {
 	var parts []string
+	duplicates := make(map[string][]string)
 	for sig, data := range sigs {
 		if len(data) <= 1 {
 			continue
@@ -69,18 +226,21 @@ func (s *Service) notifyDiscord(typ client.SignatureType, resp *client.ImportRes
 		var names []string
 		for _, name := range data {
 			names = append(names, fmt.Sprintf("`%s`", name.Name))
+			duplicates[sig] = append(duplicates[sig], name.Name)
 		}
 
 		parts = append(parts, fmt.Sprintf("`%s`: %s", sig, strings.Join(names, ", ")))
 	}
 
 	if len(parts) > 0 {
-		if _, err := s.discord.Session.ChannelMessageSendComplex(s.config.DiscordChannel, &discordgo.MessageSend{
-			Content: fmt.Sprintf("Imported the following duplicate signatures:\n%s", strings.Join(parts, "\n")),
-		}); err != nil {
-			return err
-		}
+		_ = s.notifier.Notify(context.Background(), &notify.Message{
+			Event: notify.EventDuplicateImport,
+			Title: fmt.Sprintf("Imported duplicate %s signatures", typ),
+			Text:  fmt.Sprintf("Imported the following duplicate signatures:\n%s", strings.Join(parts, "\n")),
+			Data: map[string]any{
+				"type":       typ,
+				"duplicates": duplicates,
+			},
+		})
 	}
-
-	return nil
 }
@@ -0,0 +1,151 @@
+package signature_database_srv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/auth"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/core"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/ratelimit"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/client"
+	log "github.com/sirupsen/logrus"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// route wraps a handler with the rate limits, body size limit and authentication. Every request is charged to its
+// address before anything else is done with it, so that bogus keys and oversized bodies are still rate limited, and
+// requests with a key are then also charged to the key
+func (s *Service) route(role auth.Role, next http.HandlerFunc) http.HandlerFunc {
+	authenticated := s.auth.Require(role, s.rateLimitKey(next))
+	return s.rateLimitIP(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, s.config.MaxBodySize)
+		authenticated(w, r)
+	})
+}
+
+func (s *Service) rateLimitIP(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.allow(w, r, ratelimit.IPKey(core.GetRemoteIP(r)), s.ipLimit()) {
+			next(w, r)
+		}
+	}
+}
+
+// rateLimitKey charges authenticated requests to their key, so that keys can be given limits of their own
+func (s *Service) rateLimitKey(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := auth.KeyFromContext(r.Context())
+		if key == nil || s.allow(w, r, "key:"+key.ID, s.keyLimit()) {
+			next(w, r)
+		}
+	}
+}
+
+// allow charges a request to the bucket, responding with 429 and returning false if it's over the limit
+func (s *Service) allow(w http.ResponseWriter, r *http.Request, bucket string, limit ratelimit.Limit) bool {
+	if s.limiter == nil {
+		return true
+	}
+
+	ok, wait, err := s.limiter.Allow(r.Context(), bucket, limit)
+	if err != nil {
+		// rather serve too much than nothing at all if the backend is unavailable
+		log.WithError(err).Warnf("failed to check rate limit")
+	} else if !ok {
+		failRateLimited(w, wait)
+		return false
+	}
+	return true
+}
+
+func failRateLimited(w http.ResponseWriter, wait time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	fail(w, http.StatusTooManyRequests, nil, "rate limit exceeded")
+}
+
+// rateLimitedError is returned when the work a request turns out to need is more than its bucket has left
+type rateLimitedError struct {
+	wait time.Duration
+}
+
+func (e *rateLimitedError) Error() string {
+	return fmt.Sprintf("rate limit exceeded, retry in %s", e.wait)
+}
+
+// chargeWork charges a request which costs more than usual to the bucket. The request has already been charged one
+// token, so only the rest of its work is taken, and never more than the burst so that anything within the size limits
+// can eventually be served
+func (s *Service) chargeWork(ctx context.Context, bucket string, limit ratelimit.Limit, work int) error {
+	if s.limiter == nil || work <= 1 {
+		return nil
+	}
+
+	limit.Cost = work - 1
+	if limit.Cost > limit.Burst {
+		limit.Cost = limit.Burst
+	}
+	ok, wait, err := s.limiter.Allow(ctx, bucket, limit)
+	if err != nil {
+		log.WithError(err).Warnf("failed to check rate limit")
+	} else if !ok {
+		return &rateLimitedError{wait: wait}
+	}
+	return nil
+}
+
+// failDecode responds to a body which couldn't be decoded, distinguishing those which were cut off for being too large
+func failDecode(w http.ResponseWriter, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		fail(w, http.StatusRequestEntityTooLarge, err, fmt.Sprintf("body too large: at most %d bytes may be sent", maxBytesErr.Limit))
+		return
+	}
+	fail(w, http.StatusBadRequest, err, "failed to decode body")
+}
+
+func (s *Service) ipLimit() ratelimit.Limit {
+	return ratelimit.Limit{Rate: s.config.IPRateLimit, Burst: s.config.IPRateBurst}
+}
+
+func (s *Service) keyLimit() ratelimit.Limit {
+	return ratelimit.Limit{Rate: s.config.KeyRateLimit, Burst: s.config.KeyRateBurst}
+}
+
+// workBucket returns the bucket which the extra work of an expensive request is charged to, and its limit. That's its
+// key's if it has one, since keys are given limits to suit how they're used, and otherwise its address's
+func (s *Service) workBucket(ctx context.Context, ip string) (string, ratelimit.Limit) {
+	if key := auth.KeyFromContext(ctx); key != nil {
+		return "key:" + key.ID, s.keyLimit()
+	}
+	return ratelimit.IPKey(ip), s.ipLimit()
+}
+
+func (s *Service) checkLookupSize(count int) error {
+	if count > s.config.MaxLookupHashes {
+		return fmt.Errorf("too many hashes: at most %d may be looked up at once", s.config.MaxLookupHashes)
+	}
+	return nil
+}
+
+// checkSourceSize rejects sources too large to be worth compiling. The number of signatures can only be checked once
+// they're compiled, so this is what keeps oversized requests from downloading a compiler and running it first
+func (s *Service) checkSourceSize(req *client.ImportSourceRequest) error {
+	if len(req.Source)+len(req.Input) > s.config.MaxSourceSize {
+		return fmt.Errorf("source too large: at most %d bytes may be compiled at once", s.config.MaxSourceSize)
+	}
+	return nil
+}
+
+func (s *Service) checkImportSize(req client.ImportRequest) error {
+	count := 0
+	for _, sigs := range req {
+		count += len(sigs)
+	}
+	if count > s.config.MaxImportSize {
+		return fmt.Errorf("too many signatures: at most %d may be imported at once", s.config.MaxImportSize)
+	}
+	return nil
+}
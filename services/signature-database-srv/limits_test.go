@@ -0,0 +1,70 @@
+package signature_database_srv
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/auth"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_Route(t *testing.T) {
+	s := &Service{
+		config: &Config{MaxBodySize: 16, IPRateLimit: 1, IPRateBurst: 2, KeyRateLimit: 1, KeyRateBurst: 2},
+		auth: auth.New(
+			auth.WithAnonymousRole(auth.RoleReadOnly),
+			auth.WithFailureHandler(func(w http.ResponseWriter, status int, err error) {
+				fail(w, status, err, err.Error())
+			}),
+		),
+		limiter: ratelimit.NewMemoryLimiter(),
+	}
+
+	handler := s.route(auth.RoleReadOnly, func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			failDecode(w, err)
+			return
+		}
+		succeed(w, req)
+	})
+
+	request := func(body string, token string) int {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, request(`{"a": "`+strings.Repeat("a", 32)+`"}`, ""))
+
+	// bogus keys are still charged to the address, rather than being turned away before the rate limit sees them
+	assert.Equal(t, http.StatusUnauthorized, request(`{}`, "unknown.secret"))
+	assert.Equal(t, http.StatusTooManyRequests, request(`{}`, "unknown.secret"))
+	assert.Equal(t, http.StatusTooManyRequests, request(`{}`, ""))
+}
+
+func Test_ChargeWork(t *testing.T) {
+	s := &Service{limiter: ratelimit.NewMemoryLimiter()}
+	limit := ratelimit.Limit{Rate: 1, Burst: 4}
+
+	// a request's first token was taken when it was routed
+	assert.NoError(t, s.chargeWork(context.Background(), "a", limit, 1))
+	assert.NoError(t, s.chargeWork(context.Background(), "a", limit, 4))
+
+	var limited *rateLimitedError
+	assert.True(t, errors.As(s.chargeWork(context.Background(), "a", limit, 3), &limited))
+	assert.InDelta(t, 1, limited.wait.Seconds(), 0.1)
+
+	// work beyond the burst costs the whole burst rather than never being allowed
+	assert.NoError(t, s.chargeWork(context.Background(), "b", limit, 100))
+	assert.Error(t, s.chargeWork(context.Background(), "b", limit, 2))
+}
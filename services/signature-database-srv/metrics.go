@@ -0,0 +1,69 @@
+package signature_database_srv
+
+import (
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/client"
+	"github.com/prometheus/client_golang/prometheus"
+	"time"
+)
+
+// serviceMetrics are exported alongside the request and database pool metrics every service has
+type serviceMetrics struct {
+	lookups *prometheus.CounterVec
+	imports *prometheus.CounterVec
+}
+
+func newServiceMetrics() *serviceMetrics {
+	return &serviceMetrics{
+		lookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "signature_lookups_total",
+			Help: "Hashes looked up, by type and whether any signatures were found.",
+		}, []string{"type", "result"}),
+		imports: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "signature_imports_total",
+			Help: "Signatures submitted for import, by type and whether they were imported, duplicated or invalid.",
+		}, []string{"type", "result"}),
+	}
+}
+
+func (s *Service) registerMetrics() {
+	s.monitor.MustRegister(s.metrics.lookups, s.metrics.imports)
+	s.monitor.AddDatabase(s.db)
+
+	s.monitor.AddAge("canonical_refresh", "Time since the canonical signatures were last refreshed.", s.config.CanonicalMaxAge, func() time.Time {
+		s.canonicalSignaturesLock.RLock()
+		defer s.canonicalSignaturesLock.RUnlock()
+
+		return s.lastCanonicalSignaturesRefresh
+	})
+	s.monitor.AddAge("export", "Time since the export was last brought up to date.", s.config.ExportMaxAge, func() time.Time {
+		s.dataExportLock.Lock()
+		defer s.dataExportLock.Unlock()
+
+		return s.lastExport
+	})
+}
+
+// lookupSignatures loads the signatures for hashes which were asked for, rather than those being loaded internally
+func (s *Service) lookupSignatures(typ client.SignatureType, hashes []string) (map[string][]*client.SignatureData, error) {
+	sigs, err := s.db.LoadSignatures(typ, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	found := 0
+	for _, hash := range hashes {
+		if len(sigs[hash]) > 0 {
+			found++
+		}
+	}
+	s.metrics.lookups.WithLabelValues(string(typ), "found").Add(float64(found))
+	s.metrics.lookups.WithLabelValues(string(typ), "missing").Add(float64(len(hashes) - found))
+
+	return sigs, nil
+}
+
+func (s *Service) recordImport(typ client.SignatureType, resp *client.ImportResponseDetails) {
+	s.metrics.imports.WithLabelValues(string(typ), "imported").Add(float64(len(resp.Imported)))
+	s.metrics.imports.WithLabelValues(string(typ), "duplicated").Add(float64(len(resp.Duplicated)))
+	s.metrics.imports.WithLabelValues(string(typ), "invalid").Add(float64(len(resp.Invalid)))
+}
@@ -0,0 +1,4 @@
+// Package pb holds the generated grpc api. Regenerate it with protoc-gen-go v1.28.1 and protoc-gen-go-grpc v1.2.0
+package pb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative signature_database.proto
@@ -0,0 +1,281 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.21.12
+// source: signature_database.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// SignatureDatabaseClient is the client API for SignatureDatabase service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SignatureDatabaseClient interface {
+	Lookup(ctx context.Context, in *LookupRequest, opts ...grpc.CallOption) (*LookupResponse, error)
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+	Import(ctx context.Context, in *ImportRequest, opts ...grpc.CallOption) (*ImportResponse, error)
+	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error)
+	// Export streams the latest full export snapshot, ordered by hash, or the signatures added since since_id or since
+	// up to the current export version, ordered by id
+	Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (SignatureDatabase_ExportClient, error)
+}
+
+type signatureDatabaseClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSignatureDatabaseClient(cc grpc.ClientConnInterface) SignatureDatabaseClient {
+	return &signatureDatabaseClient{cc}
+}
+
+func (c *signatureDatabaseClient) Lookup(ctx context.Context, in *LookupRequest, opts ...grpc.CallOption) (*LookupResponse, error) {
+	out := new(LookupResponse)
+	err := c.cc.Invoke(ctx, "/signaturedatabase.v1.SignatureDatabase/Lookup", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signatureDatabaseClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, "/signaturedatabase.v1.SignatureDatabase/Search", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signatureDatabaseClient) Import(ctx context.Context, in *ImportRequest, opts ...grpc.CallOption) (*ImportResponse, error) {
+	out := new(ImportResponse)
+	err := c.cc.Invoke(ctx, "/signaturedatabase.v1.SignatureDatabase/Import", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signatureDatabaseClient) Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error) {
+	out := new(StatsResponse)
+	err := c.cc.Invoke(ctx, "/signaturedatabase.v1.SignatureDatabase/Stats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signatureDatabaseClient) Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (SignatureDatabase_ExportClient, error) {
+	stream, err := c.cc.NewStream(ctx, &SignatureDatabase_ServiceDesc.Streams[0], "/signaturedatabase.v1.SignatureDatabase/Export", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &signatureDatabaseExportClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type SignatureDatabase_ExportClient interface {
+	Recv() (*ExportedSignature, error)
+	grpc.ClientStream
+}
+
+type signatureDatabaseExportClient struct {
+	grpc.ClientStream
+}
+
+func (x *signatureDatabaseExportClient) Recv() (*ExportedSignature, error) {
+	m := new(ExportedSignature)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// SignatureDatabaseServer is the server API for SignatureDatabase service.
+// All implementations must embed UnimplementedSignatureDatabaseServer
+// for forward compatibility
+type SignatureDatabaseServer interface {
+	Lookup(context.Context, *LookupRequest) (*LookupResponse, error)
+	Search(context.Context, *SearchRequest) (*SearchResponse, error)
+	Import(context.Context, *ImportRequest) (*ImportResponse, error)
+	Stats(context.Context, *StatsRequest) (*StatsResponse, error)
+	// Export streams the latest full export snapshot, ordered by hash, or the signatures added since since_id or since
+	// up to the current export version, ordered by id
+	Export(*ExportRequest, SignatureDatabase_ExportServer) error
+	mustEmbedUnimplementedSignatureDatabaseServer()
+}
+
+// UnimplementedSignatureDatabaseServer must be embedded to have forward compatible implementations.
+type UnimplementedSignatureDatabaseServer struct {
+}
+
+func (UnimplementedSignatureDatabaseServer) Lookup(context.Context, *LookupRequest) (*LookupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Lookup not implemented")
+}
+func (UnimplementedSignatureDatabaseServer) Search(context.Context, *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedSignatureDatabaseServer) Import(context.Context, *ImportRequest) (*ImportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Import not implemented")
+}
+func (UnimplementedSignatureDatabaseServer) Stats(context.Context, *StatsRequest) (*StatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stats not implemented")
+}
+func (UnimplementedSignatureDatabaseServer) Export(*ExportRequest, SignatureDatabase_ExportServer) error {
+	return status.Errorf(codes.Unimplemented, "method Export not implemented")
+}
+func (UnimplementedSignatureDatabaseServer) mustEmbedUnimplementedSignatureDatabaseServer() {}
+
+// UnsafeSignatureDatabaseServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SignatureDatabaseServer will
+// result in compilation errors.
+type UnsafeSignatureDatabaseServer interface {
+	mustEmbedUnimplementedSignatureDatabaseServer()
+}
+
+func RegisterSignatureDatabaseServer(s grpc.ServiceRegistrar, srv SignatureDatabaseServer) {
+	s.RegisterService(&SignatureDatabase_ServiceDesc, srv)
+}
+
+func _SignatureDatabase_Lookup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LookupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SignatureDatabaseServer).Lookup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/signaturedatabase.v1.SignatureDatabase/Lookup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SignatureDatabaseServer).Lookup(ctx, req.(*LookupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SignatureDatabase_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SignatureDatabaseServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/signaturedatabase.v1.SignatureDatabase/Search",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SignatureDatabaseServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SignatureDatabase_Import_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SignatureDatabaseServer).Import(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/signaturedatabase.v1.SignatureDatabase/Import",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SignatureDatabaseServer).Import(ctx, req.(*ImportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SignatureDatabase_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SignatureDatabaseServer).Stats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/signaturedatabase.v1.SignatureDatabase/Stats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SignatureDatabaseServer).Stats(ctx, req.(*StatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SignatureDatabase_Export_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SignatureDatabaseServer).Export(m, &signatureDatabaseExportServer{stream})
+}
+
+type SignatureDatabase_ExportServer interface {
+	Send(*ExportedSignature) error
+	grpc.ServerStream
+}
+
+type signatureDatabaseExportServer struct {
+	grpc.ServerStream
+}
+
+func (x *signatureDatabaseExportServer) Send(m *ExportedSignature) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// SignatureDatabase_ServiceDesc is the grpc.ServiceDesc for SignatureDatabase service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SignatureDatabase_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "signaturedatabase.v1.SignatureDatabase",
+	HandlerType: (*SignatureDatabaseServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Lookup",
+			Handler:    _SignatureDatabase_Lookup_Handler,
+		},
+		{
+			MethodName: "Search",
+			Handler:    _SignatureDatabase_Search_Handler,
+		},
+		{
+			MethodName: "Import",
+			Handler:    _SignatureDatabase_Import_Handler,
+		},
+		{
+			MethodName: "Stats",
+			Handler:    _SignatureDatabase_Stats_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Export",
+			Handler:       _SignatureDatabase_Export_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "signature_database.proto",
+}
@@ -1,15 +1,20 @@
 package signature_database_srv
 
 import (
+	"context"
+	"encoding/hex"
 	"fmt"
-	"github.com/google/uuid"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/auth"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/core"
 	"github.com/openchainxyz/openchainxyz-monorepo/internal/discord"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/monitoring"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/notify"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/ratelimit"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/client"
 	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/database"
 	log "github.com/sirupsen/logrus"
-	"gopkg.in/yaml.v3"
 	"net/http"
-	"os"
-	"path"
 	"sync"
 	"time"
 )
@@ -21,24 +26,118 @@ type Config struct {
 	DatabaseUser     string `def:"ethereum" env:"DB_USER"`
 	DatabasePassword string `def:"ethereum" env:"DB_PASS"`
 	HttpPort         int    `def:"34887" env:"PORT"`
-	DiscordBotToken  string `env:"DISCORD_BOT_TOKEN"`
-	DiscordChannel   string `env:"DISCORD_CHANNEL"`
-
-	DataDumpDir string `env:"DATA_DUMP_DIR"`
+	GrpcPort         int    `env:"GRPC_PORT"` // the grpc api is only served if this is set
+	// MetricsPort serves /metrics, /healthz and /readyz, which aren't authenticated so shouldn't be exposed publicly
+	MetricsPort     int    `def:"34888" env:"METRICS_PORT"`
+	DiscordBotToken string `env:"DISCORD_BOT_TOKEN"`
+	// DiscordGuild limits slash commands to one server, where they update immediately rather than within an hour
+	DiscordGuild string `env:"DISCORD_GUILD"`
+	// DiscordChannel is shorthand for a "discord:<channel id>;events=duplicate_import" notifier
+	DiscordChannel string `env:"DISCORD_CHANNEL"`
+	// DiscordImportGuilds and DiscordImportRoles are the ids of the guilds whose members, and the roles whose holders,
+	// may use /import even though the anonymous role can't import
+	DiscordImportGuilds []string `env:"DISCORD_IMPORT_GUILDS"`
+	DiscordImportRoles  []string `env:"DISCORD_IMPORT_ROLES"`
+
+	DataDumpDir    string        `env:"DATA_DUMP_DIR"`
+	ExportInterval time.Duration `def:"24h" env:"EXPORT_INTERVAL"`
+
+	// CanonicalMaxAge and ExportMaxAge fail /readyz once this replica's canonical signatures or export haven't been
+	// brought up to date for that long. They're off by default, since a cause every replica shares, like a canonical
+	// source being down, takes them all out of rotation at once
+	CanonicalMaxAge time.Duration `env:"CANONICAL_MAX_AGE"`
+	ExportMaxAge    time.Duration `env:"EXPORT_MAX_AGE"`
+
+	// ApiKeys are keys of the form "<name>:<role>:<id>.<secret>" which are accepted alongside those in the database
+	ApiKeys []string `env:"API_KEYS"`
+	// KeyEncryptionKey is the hex encoded aes key which signing keys are encrypted with in the database. Keys created
+	// without one can only be presented as bearer tokens
+	KeyEncryptionKey string `env:"KEY_ENCRYPTION_KEY"`
+	// AnonymousRole is the role given to requests without a key. Set it to "none" to make the instance private, or
+	// "importer" to let anyone submit signatures
+	AnonymousRole string   `def:"read_only" env:"ANONYMOUS_ROLE"`
+	CorsOrigins   []string `def:"*" env:"CORS_ORIGINS"`
+
+	// TrustedProxies are the CIDRs whose forwarding headers (X-Forwarded-For, X-Real-IP) are believed
+	TrustedProxies []string `def:"127.0.0.0/8,::1/128" env:"TRUSTED_PROXIES"`
+	// TrustCloudflare also believes CF-Connecting-IP from the trusted proxies, which should then be cloudflare's ranges
+	TrustCloudflare bool `def:"false" env:"TRUST_CLOUDFLARE"`
+	// RateLimitBackend is one of "memory", "postgres" (shared between replicas) or "none". It's off by default, since
+	// clients are limited by address and without TrustedProxies covering the load balancer in front of the service,
+	// every client would share the load balancer's limit
+	RateLimitBackend string  `def:"none" env:"RATE_LIMIT_BACKEND"`
+	IPRateLimit      float64 `def:"10" env:"IP_RATE_LIMIT"`
+	IPRateBurst      int     `def:"50" env:"IP_RATE_BURST"`
+	KeyRateLimit     float64 `def:"100" env:"KEY_RATE_LIMIT"`
+	KeyRateBurst     int     `def:"500" env:"KEY_RATE_BURST"`
+	MaxBodySize      int64   `def:"8388608" env:"MAX_BODY_SIZE"`
+	MaxLookupHashes  int     `def:"250" env:"MAX_LOOKUP_HASHES"`
+	MaxImportSize    int     `def:"5000" env:"MAX_IMPORT_SIZE"`
+	// MaxSourceSize caps the source submitted to /v1/import/source, which is checked before anything is compiled
+	MaxSourceSize int `def:"1048576" env:"MAX_SOURCE_SIZE"`
+	// CompileTimeout bounds how long /v1/import/source spends downloading a compiler and compiling with it
+	CompileTimeout time.Duration `def:"60s" env:"COMPILE_TIMEOUT"`
+	// MaxDecodeDataSize caps the calldata or log data given to /v1/decode, and MaxDecodeCandidates how many signatures
+	// are tried against it. Decoding is charged to the rate limit by the candidates tried and the size of the data
+	MaxDecodeDataSize   int `def:"131072" env:"MAX_DECODE_DATA_SIZE"`
+	MaxDecodeCandidates int `def:"32" env:"MAX_DECODE_CANDIDATES"`
+	// MaxGraphQLCost is the budget of a /graphql request, across every query in it. Each hash looked up and each search
+	// result or collision which may be returned costs one, and stats costs 100
+	MaxGraphQLCost int `def:"2000" env:"MAX_GRAPHQL_COST"`
+
+	// AuditMode is what the periodic consistency audit does with mismatched rows: "flag", "repair" or "quarantine"
+	AuditMode     string        `def:"flag" env:"AUDIT_MODE"`
+	AuditInterval time.Duration `def:"24h" env:"AUDIT_INTERVAL"`
+
+	// SyncUpstream is the base url of another instance to mirror, such as https://api.openchain.xyz/signature-database
+	SyncUpstream string        `env:"SYNC_UPSTREAM"`
+	SyncApiKey   string        `env:"SYNC_API_KEY"`
+	SyncInterval time.Duration `def:"10m" env:"SYNC_INTERVAL"`
+
+	// Notifiers are where alerts are sent, as a list of "log:", "discord:<channel id>", "slack:<url>" or
+	// "webhook:<url>[;secret=<hmac secret>]". Each may be limited to some events with ";events=<event>|<event>", out
+	// of duplicate_import, collision, export_completed and canonical_refresh_failed
+	Notifiers []string `env:"NOTIFIERS"`
+	// CollisionPopularScore is the score from which a signature is considered established, so that a new name
+	// colliding with it is reported as suspicious. Scores count the observer keys which reported the signature
+	CollisionPopularScore int64 `def:"1" env:"COLLISION_POPULAR_SCORE"`
+
+	// CanonicalSignatureSources is a list of urls or file paths, with later sources taking precedence
+	CanonicalSignatureSources []string `def:"https://raw.githubusercontent.com/openchainxyz/canonical-signatures/main/canonical.yaml" env:"CANONICAL_SIGNATURE_SOURCES"`
 }
 
 type Service struct {
-	config  *Config
-	db      *database.Database
-	discord *discord.Client
+	config   *Config
+	db       *database.Database
+	discord  *discord.Client
+	notifier notify.Notifier
+	auth     *auth.Authenticator
+	keys     *auth.DatabaseKeyStore
+	proxies  core.TrustedProxies
+	limiter  ratelimit.Limiter
+	syncer   *syncer
+	graphql  *graphql.Schema
+	monitor  *monitoring.Monitor
+	metrics  *serviceMetrics
+
+	// errs receives the reason any of the servers stopped
+	errs chan error
 
 	canonicalSignaturesLock        sync.RWMutex
 	canonicalSignatures            map[string]string
+	canonicalSignatureSources      map[string]map[string]*canonicalSignature
 	lastCanonicalSignaturesRefresh time.Time
 
-	dataExportLock     sync.Mutex
-	dataExportPath     string
-	lastDataExportTime time.Time
+	auditReportLock   sync.Mutex
+	auditReport       *client.AuditReport
+	auditReportLoaded time.Time
+
+	// exportLock is held while a snapshot is taken, and dataExportLock while the latest one is swapped in or out
+	exportLock     sync.Mutex
+	dataExportLock sync.Mutex
+	dataExport     *exportSnapshot
+	exportVersion  int64
+	lastExport     time.Time
 }
 
 func New(config *Config) (*Service, error) {
@@ -47,121 +146,180 @@ func New(config *Config) (*Service, error) {
 		return nil, fmt.Errorf("failed to create database: %w", err)
 	}
 
-	service := &Service{
-		config: config,
-		db:     db,
-
-		canonicalSignaturesLock: sync.RWMutex{},
-		canonicalSignatures:     make(map[string]string),
+	anonymousRole := auth.Role(config.AnonymousRole)
+	if !anonymousRole.Valid() {
+		return nil, fmt.Errorf("invalid anonymous role: %s", config.AnonymousRole)
+	}
 
-		dataExportLock: sync.Mutex{},
+	staticKeys, err := auth.ParseStaticKeys(config.ApiKeys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse api keys: %w", err)
 	}
 
-	if config.DiscordBotToken != "" {
-		discordClient, err := discord.New(config.DiscordBotToken)
+	var encryptionKey []byte
+	if config.KeyEncryptionKey != "" {
+		encryptionKey, err = hex.DecodeString(config.KeyEncryptionKey)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create discord bot: %w", err)
+			return nil, fmt.Errorf("invalid key encryption key: %w", err)
 		}
-		service.discord = discordClient
 	}
 
-	if err := service.loadCanonicalSignatures(); err != nil {
-		return nil, fmt.Errorf("failed to load canonical signatures: %w", err)
+	keys, err := db.KeyStore(encryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key store: %w", err)
 	}
 
-	return service, nil
-}
+	if !client.AuditMode(config.AuditMode).Valid() {
+		return nil, fmt.Errorf("invalid audit mode: %s", config.AuditMode)
+	}
 
-func (s *Service) Start() error {
-	go s.startServer()
-	go s.runTasks()
+	proxies, err := core.ParseTrustedProxies(config.TrustedProxies, config.TrustCloudflare)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse trusted proxies: %w", err)
+	}
 
-	return nil
-}
+	var limiter ratelimit.Limiter
+	switch config.RateLimitBackend {
+	case "memory":
+		limiter = ratelimit.NewMemoryLimiter()
+	case "postgres":
+		limiter = db.RateLimiter()
+	case "none":
+	default:
+		return nil, fmt.Errorf("invalid rate limit backend: %s", config.RateLimitBackend)
+	}
 
-func (s *Service) loadCanonicalSignatures() error {
-	s.canonicalSignaturesLock.Lock()
-	lastRefresh := s.lastCanonicalSignaturesRefresh
-	s.canonicalSignaturesLock.Unlock()
+	service := &Service{
+		config: config,
+		db:     db,
+		auth: auth.New(
+			auth.WithKeyStore(staticKeys),
+			auth.WithKeyStore(keys),
+			auth.WithAnonymousRole(anonymousRole),
+			auth.WithFailureHandler(func(w http.ResponseWriter, status int, err error) {
+				fail(w, status, err, err.Error())
+			}),
+		),
+		keys:    keys,
+		proxies: proxies,
+		limiter: limiter,
+		monitor: monitoring.New("signature-database-srv"),
+		metrics: newServiceMetrics(),
+		errs:    make(chan error),
+
+		canonicalSignaturesLock:   sync.RWMutex{},
+		canonicalSignatures:       make(map[string]string),
+		canonicalSignatureSources: make(map[string]map[string]*canonicalSignature),
 
-	if time.Since(lastRefresh) < 1*time.Hour {
-		return fmt.Errorf("refreshing too soon")
+		dataExportLock: sync.Mutex{},
+		exportVersion:  -1,
 	}
 
-	resp, err := http.Get("https://raw.githubusercontent.com/openchainxyz/canonical-signatures/main/canonical.yaml")
+	service.registerMetrics()
+
+	service.graphql, err = newGraphQLSchema(service)
 	if err != nil {
-		return fmt.Errorf("failed to fetch canonical signatures: %w", err)
+		return nil, fmt.Errorf("failed to parse graphql schema: %w", err)
 	}
 
-	var output map[string]struct {
-		Signature string `yaml:"signature"`
-		Source    string `yaml:"source"`
+	if config.SyncUpstream != "" {
+		service.syncer = newSyncer(config.SyncUpstream, config.SyncApiKey, db)
 	}
 
-	if err := yaml.NewDecoder(resp.Body).Decode(&output); err != nil {
-		return fmt.Errorf("failed to unmarshal yaml: %w", err)
+	if config.DiscordBotToken != "" {
+		discordClient, err := discord.New(
+			config.DiscordBotToken,
+			discord.WithCommands(service.discordCommands()...),
+			discord.WithGuild(config.DiscordGuild),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create discord bot: %w", err)
+		}
+		service.discord = discordClient
 	}
 
-	newCanonicalSignatures := make(map[string]string)
-	for k, v := range output {
-		newCanonicalSignatures[k] = v.Signature
+	var sinks []*notify.Sink
+	for _, spec := range config.Notifiers {
+		sink, err := notify.Parse(spec, service.discord)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse notifier %s: %w", spec, err)
+		}
+		sinks = append(sinks, sink)
 	}
+	if config.DiscordChannel != "" && service.discord == nil {
+		log.Warnf("ignoring discord channel, as there's no discord bot token to post to it with")
+	} else if config.DiscordChannel != "" {
+		sinks = append(sinks, &notify.Sink{
+			Notifier: notify.NewDiscordNotifier(service.discord, config.DiscordChannel),
+			Events:   []notify.Event{notify.EventDuplicateImport},
+		})
+	}
+	service.notifier = notify.NewRouter(sinks...)
 
-	s.canonicalSignaturesLock.Lock()
-	s.canonicalSignatures = newCanonicalSignatures
-	s.lastCanonicalSignaturesRefresh = time.Now()
-	s.canonicalSignaturesLock.Unlock()
-
-	return nil
-}
-
-func (s *Service) exportData() error {
-	s.dataExportLock.Lock()
-	lastExportTime := s.lastDataExportTime
-	s.dataExportLock.Unlock()
+	// without fresh canonical signatures we'll just filter less aggressively, so don't refuse to start
+	if err := service.loadCanonicalSignatures(); err != nil {
+		log.WithError(err).Warnf("failed to refresh canonical signatures, starting in degraded mode")
 
-	if time.Since(lastExportTime) < 24*time.Hour {
-		return fmt.Errorf("exporting too soon")
+		if err := service.reloadCanonicalSignatures(); err != nil {
+			log.WithError(err).Warnf("failed to load canonical signatures from database")
+		}
 	}
 
-	newPath := path.Join(s.config.DataDumpDir, uuid.New().String()+".txt")
+	return service, nil
+}
 
-	f, err := os.Create(newPath)
-	if err != nil {
+func (s *Service) Start() error {
+	if err := s.startServer(); err != nil {
 		return err
 	}
-
-	if err := s.db.ExportData(f); err != nil {
-		f.Close()
-		return err
+	if s.config.GrpcPort != 0 {
+		if err := s.startGrpcServer(); err != nil {
+			return err
+		}
 	}
-
-	if err := f.Close(); err != nil {
-		return err
+	go s.runTasks()
+	go s.runExports()
+	go s.runExportVersion()
+	go s.runAudits()
+	go s.runCollisionScans()
+	if s.syncer != nil {
+		go s.runSync()
+	}
+	if s.limiter != nil {
+		go s.pruneRateLimits()
 	}
 
-	s.dataExportLock.Lock()
-	lastPath := s.dataExportPath
-	s.dataExportPath = newPath
-	s.lastDataExportTime = time.Now()
-	s.dataExportLock.Unlock()
+	return nil
+}
 
-	os.Remove(lastPath)
+// Wait blocks until one of the servers started by Start stops, returning why
+func (s *Service) Wait() error {
+	return <-s.errs
+}
 
-	return nil
+func (s *Service) pruneRateLimits() {
+	ticker := time.NewTicker(10 * time.Minute)
+	for range ticker.C {
+		if err := s.limiter.Prune(context.Background()); err != nil {
+			log.WithError(err).Errorf("failed to prune rate limits")
+		}
+	}
 }
+
 func (s *Service) runTasks() {
-	ticker := time.NewTicker(24 * time.Hour)
+	ticker := time.NewTicker(canonicalRefreshInterval)
 	for ; true; <-ticker.C {
 		if err := s.loadCanonicalSignatures(); err != nil {
 			log.WithError(err).Errorf("failed to load canonical signatures")
+
+			_ = s.notifier.Notify(context.Background(), &notify.Message{
+				Event: notify.EventCanonicalRefreshFailed,
+				Title: "Canonical signature refresh failed",
+				Text:  fmt.Sprintf("`%s`", err),
+				Data:  map[string]string{"error": err.Error()},
+			})
 		} else {
 			log.Info("successfully refreshed canonical signatures")
 		}
-		if err := s.exportData(); err != nil {
-			log.WithError(err).Errorf("failed to export data")
-		} else {
-			log.Info("successfully exported data")
-		}
 	}
 }
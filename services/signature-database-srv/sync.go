@@ -0,0 +1,170 @@
+package signature_database_srv
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/solidity"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/client"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/database"
+	log "github.com/sirupsen/logrus"
+	"io"
+	"strings"
+	"time"
+)
+
+const syncBatchSize = 5000
+
+// syncStore is the part of the database which the syncer needs
+type syncStore interface {
+	SaveSignatures(typ client.SignatureType, names []string, meta *database.SignatureMetadata) (*client.ImportResponseDetails, error)
+	LoadSyncState(upstream string) (map[client.SignatureType]int64, error)
+	SaveSyncState(upstream string, typ client.SignatureType, lastID int64) error
+	RemoveSignature(typ client.SignatureType, name string, hash []byte) (bool, error)
+}
+
+// syncer mirrors an upstream instance by following its incremental export. Progress is saved after every batch, so a
+// restart picks up where the last run stopped
+type syncer struct {
+	upstream  string
+	client    *client.Client
+	store     syncStore
+	batchSize int
+}
+
+func newSyncer(upstream string, apiKey string, store syncStore) *syncer {
+	var options []client.Option
+	if apiKey != "" {
+		options = append(options, client.WithAPIKey(apiKey))
+	}
+
+	return &syncer{
+		upstream:  strings.TrimSuffix(upstream, "/"),
+		client:    client.NewWithHost(upstream, options...),
+		store:     store,
+		batchSize: syncBatchSize,
+	}
+}
+
+func (s *Service) runSync() {
+	ticker := time.NewTicker(s.config.SyncInterval)
+	for ; true; <-ticker.C {
+		if err := s.syncer.sync(context.Background()); err != nil {
+			log.WithError(err).WithField("upstream", s.syncer.upstream).Errorf("failed to sync from upstream")
+		}
+	}
+}
+
+func (s *syncer) sync(ctx context.Context) error {
+	state, err := s.store.LoadSyncState(s.upstream)
+	if err != nil {
+		return fmt.Errorf("failed to load sync state: %w", err)
+	}
+
+	for _, typ := range client.SignatureTypes() {
+		if err := s.syncType(ctx, typ, state[typ]); err != nil {
+			return fmt.Errorf("failed to sync %s signatures: %w", typ, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *syncer) syncType(ctx context.Context, typ client.SignatureType, lastID int64) error {
+	export, err := s.client.Export(ctx, &client.ExportOptions{
+		Type:    typ,
+		SinceID: &lastID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start export: %w", err)
+	}
+	defer export.Close()
+
+	var (
+		batch    []string
+		imported int
+		rejected int
+		removed  int
+	)
+	flush := func(upTo int64) error {
+		if len(batch) > 0 {
+			res, err := s.store.SaveSignatures(typ, batch, &database.SignatureMetadata{
+				Source:    client.SignatureSourceSync,
+				Submitter: s.upstream,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to save signatures: %w", err)
+			}
+			imported += len(res.Imported)
+			batch = batch[:0]
+		}
+
+		if upTo > lastID {
+			if err := s.store.SaveSyncState(s.upstream, typ, upTo); err != nil {
+				return fmt.Errorf("failed to save sync state: %w", err)
+			}
+			lastID = upTo
+		}
+		return nil
+	}
+
+	var seen int64
+	for {
+		sig, err := export.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			// the rows read before an export was cut short are whole, so keep them rather than fetching them again
+			if err := flush(seen); err != nil {
+				return err
+			}
+			return err
+		}
+
+		if sig.Removed {
+			// removals are applied in order with what's been added, and only remove a row with the same hash
+			hash, err := hexutil.Decode(sig.Hash)
+			if sig.Type != typ || err != nil {
+				rejected++
+			} else if err := flush(seen); err != nil {
+				return err
+			} else if ok, err := s.store.RemoveSignature(typ, sig.Name, hash); err != nil {
+				return fmt.Errorf("failed to remove signature: %w", err)
+			} else if ok {
+				removed++
+			}
+		} else if sig.Type != typ || !solidity.VerifySignature(sig.Name) || hashSignature(typ, sig.Name) != strings.ToLower(sig.Hash) {
+			// the hash is recomputed when saving, but a mismatch means the upstream can't be trusted with this row
+			rejected++
+		} else {
+			batch = append(batch, sig.Name)
+		}
+
+		seen = sig.ID
+		if len(batch) >= s.batchSize {
+			if err := flush(seen); err != nil {
+				return err
+			}
+		}
+	}
+	// everything up to the version has been seen, even if the last few ids belonged to other types
+	if err := flush(export.Version); err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"upstream": s.upstream,
+		"type":     typ,
+		"imported": imported,
+		"rejected": rejected,
+		"removed":  removed,
+		"version":  export.Version,
+	}).Infof("synced from upstream")
+
+	return nil
+}
+
+func hashSignature(typ client.SignatureType, name string) string {
+	return hexutil.Encode(crypto.Keccak256([]byte(name))[:typ.HashLength()])
+}
@@ -0,0 +1,170 @@
+package signature_database_srv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/client"
+	"github.com/openchainxyz/openchainxyz-monorepo/services/signature-database-srv/database"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+type memorySyncStore struct {
+	signatures map[client.SignatureType][]string
+	state      map[client.SignatureType]int64
+}
+
+func (m *memorySyncStore) SaveSignatures(typ client.SignatureType, names []string, meta *database.SignatureMetadata) (*client.ImportResponseDetails, error) {
+	result := client.NewImportResponseDetails()
+	for _, name := range names {
+		m.signatures[typ] = append(m.signatures[typ], name)
+		result.Imported[name] = hashSignature(typ, name)
+	}
+	return result, nil
+}
+
+func (m *memorySyncStore) LoadSyncState(upstream string) (map[client.SignatureType]int64, error) {
+	state := make(map[client.SignatureType]int64)
+	for typ, id := range m.state {
+		state[typ] = id
+	}
+	return state, nil
+}
+
+func (m *memorySyncStore) SaveSyncState(upstream string, typ client.SignatureType, lastID int64) error {
+	m.state[typ] = lastID
+	return nil
+}
+
+func (m *memorySyncStore) RemoveSignature(typ client.SignatureType, name string, hash []byte) (bool, error) {
+	for i, saved := range m.signatures[typ] {
+		if saved == name && hashSignature(typ, name) == hexutil.Encode(hash) {
+			m.signatures[typ] = append(m.signatures[typ][:i], m.signatures[typ][i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// upstream is a stand-in for the export endpoint of another instance
+type upstream struct {
+	signatures []*client.ExportedSignature
+	requests   []string
+	// brokenAfter cuts exports short after this many rows with a malformed one, if set
+	brokenAfter int
+	// truncatedAfter cleanly ends exports after this many rows without their trailer, if set
+	truncatedAfter int
+}
+
+func (u *upstream) add(id int64, typ client.SignatureType, name string) {
+	u.signatures = append(u.signatures, &client.ExportedSignature{ID: id, Type: typ, Hash: hashSignature(typ, name), Name: name})
+}
+
+func (u *upstream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	u.requests = append(u.requests, r.URL.RequestURI())
+
+	since, _ := strconv.ParseInt(r.URL.Query().Get("since_id"), 10, 64)
+	typ := client.SignatureType(r.URL.Query().Get("type"))
+
+	var version int64
+	for _, sig := range u.signatures {
+		if sig.ID > version {
+			version = sig.ID
+		}
+	}
+	w.Header().Set("X-Export-Version", strconv.FormatInt(version, 10))
+
+	written := 0
+	enc := json.NewEncoder(w)
+	for _, sig := range u.signatures {
+		if sig.Type != typ || sig.ID <= since {
+			continue
+		}
+		if u.brokenAfter != 0 && written == u.brokenAfter {
+			fmt.Fprintln(w, "{broken")
+			return
+		}
+		if u.truncatedAfter != 0 && written == u.truncatedAfter {
+			return
+		}
+		enc.Encode(sig)
+		written++
+	}
+	enc.Encode(&client.ExportTrailer{End: true, Count: int64(written)})
+}
+
+func Test_Sync(t *testing.T) {
+	up := &upstream{}
+	up.add(1, client.SignatureTypeFunction, "transfer(address,uint256)")
+	up.add(2, client.SignatureTypeEvent, "Transfer(address,address,uint256)")
+	up.add(3, client.SignatureTypeFunction, "approve(address,uint256)")
+	up.add(4, client.SignatureTypeError, "InsufficientBalance(uint256,uint256)")
+	// a tampered row which must not be mirrored
+	up.signatures = append(up.signatures, &client.ExportedSignature{ID: 5, Type: client.SignatureTypeFunction, Hash: "0x12345678", Name: "evil()"})
+
+	server := httptest.NewServer(up)
+	defer server.Close()
+
+	store := &memorySyncStore{
+		signatures: make(map[client.SignatureType][]string),
+		state:      make(map[client.SignatureType]int64),
+	}
+	s := newSyncer(server.URL, "", store)
+
+	assert.NoError(t, s.sync(context.Background()))
+	assert.Equal(t, []string{"transfer(address,uint256)", "approve(address,uint256)"}, store.signatures[client.SignatureTypeFunction])
+	assert.Equal(t, []string{"Transfer(address,address,uint256)"}, store.signatures[client.SignatureTypeEvent])
+	assert.Equal(t, []string{"InsufficientBalance(uint256,uint256)"}, store.signatures[client.SignatureTypeError])
+	assert.Equal(t, int64(5), store.state[client.SignatureTypeFunction])
+	assert.Equal(t, int64(5), store.state[client.SignatureTypeEvent])
+
+	// the next sync only asks for what's new
+	up.requests = nil
+	up.add(6, client.SignatureTypeFunction, "balanceOf(address)")
+	up.add(7, client.SignatureTypeFunction, "totalSupply()")
+	up.add(8, client.SignatureTypeFunction, "decimals()")
+	up.brokenAfter = 2
+	s.batchSize = 1
+
+	assert.Error(t, s.sync(context.Background()))
+	assert.Equal(t, "/v1/export?format=ndjson&since_id=5&type=function", up.requests[0])
+	assert.Equal(t, []string{"balanceOf(address)", "totalSupply()"}, store.signatures[client.SignatureTypeFunction][2:])
+	assert.Equal(t, int64(7), store.state[client.SignatureTypeFunction])
+
+	// and a restart resumes from the last saved batch
+	up.requests = nil
+	up.brokenAfter = 0
+
+	assert.NoError(t, newSyncer(server.URL, "", store).sync(context.Background()))
+	assert.Equal(t, "/v1/export?format=ndjson&since_id=7&type=function", up.requests[0])
+	assert.Equal(t, []string{"balanceOf(address)", "totalSupply()", "decimals()"}, store.signatures[client.SignatureTypeFunction][2:])
+	assert.Equal(t, int64(8), store.state[client.SignatureTypeError])
+
+	// an export which ends early without its trailer only counts up to the last row received
+	up.requests = nil
+	up.add(9, client.SignatureTypeFunction, "name()")
+	up.add(10, client.SignatureTypeFunction, "symbol()")
+	up.truncatedAfter = 1
+
+	assert.Error(t, newSyncer(server.URL, "", store).sync(context.Background()))
+	assert.Equal(t, "/v1/export?format=ndjson&since_id=8&type=function", up.requests[0])
+	assert.Equal(t, []string{"decimals()", "name()"}, store.signatures[client.SignatureTypeFunction][4:])
+	assert.Equal(t, int64(9), store.state[client.SignatureTypeFunction])
+
+	// removals only drop a row with the same hash, once what came before them has been saved
+	up.requests = nil
+	up.truncatedAfter = 0
+	up.signatures = append(up.signatures,
+		&client.ExportedSignature{ID: 11, Type: client.SignatureTypeFunction, Hash: hashSignature(client.SignatureTypeFunction, "name()"), Name: "name()", Removed: true},
+		&client.ExportedSignature{ID: 12, Type: client.SignatureTypeFunction, Hash: "0x12345678", Name: "decimals()", Removed: true},
+	)
+
+	assert.NoError(t, newSyncer(server.URL, "", store).sync(context.Background()))
+	assert.Equal(t, []string{"decimals()", "symbol()"}, store.signatures[client.SignatureTypeFunction][4:])
+	assert.Equal(t, int64(12), store.state[client.SignatureTypeFunction])
+}
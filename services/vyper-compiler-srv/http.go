@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/auth"
 	"github.com/openchainxyz/openchainxyz-monorepo/internal/compiler"
 	"github.com/openchainxyz/openchainxyz-monorepo/services/vyper-compiler-srv/client"
-	log "github.com/sirupsen/logrus"
+	"net"
 	"net/http"
+	"time"
 )
 
 func (s *Service) serveCompile(w http.ResponseWriter, r *http.Request) {
@@ -23,7 +25,7 @@ func (s *Service) serveCompile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	vyper, err := compiler.NewVyperCompiler(request.Version)
+	vyper, err := compiler.NewVyperCompiler(r.Context(), request.Version)
 	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusBadRequest)
@@ -34,8 +36,11 @@ func (s *Service) serveCompile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	output, err := vyper.CompileFromString(request.Code)
+	start := time.Now()
+	output, err := vyper.CompileFromString(r.Context(), request.Code)
 	if err != nil {
+		s.compileDurations.WithLabelValues("failed").Observe(time.Since(start).Seconds())
+
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(map[string]any{
@@ -45,6 +50,8 @@ func (s *Service) serveCompile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	s.compileDurations.WithLabelValues("success").Observe(time.Since(start).Seconds())
+
 	obj := output["/dev/stdin"]
 
 	w.Header().Set("Content-Type", "application/json")
@@ -57,19 +64,34 @@ func (s *Service) serveCompile(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-func (s *Service) startServer() {
+func (s *Service) startServer() error {
 	m := mux.NewRouter()
-	m.HandleFunc("/v1/compile", s.serveCompile).Methods("POST")
+	s.monitor.Instrument(m)
+	m.HandleFunc("/v1/compile", s.auth.Require(auth.RoleReadOnly, s.serveCompile)).Methods("POST")
 
 	cors := handlers.CORS(
 		handlers.AllowedMethods([]string{"OPTIONS", "HEAD", "GET", "POST"}),
 		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedHeaders([]string{"X-Requested-With", "Content-Type"}),
+		handlers.AllowedHeaders([]string{"X-Requested-With", "Content-Type", "Authorization", auth.HeaderKeyID, auth.HeaderTimestamp, auth.HeaderSignature}),
 	)(m)
 
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", s.config.HttpPort))
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	monitoringListener, err := net.Listen("tcp", fmt.Sprintf(":%d", s.config.MetricsPort))
+	if err != nil {
+		listener.Close()
+		return fmt.Errorf("failed to listen for monitoring: %w", err)
+	}
+
 	go func() {
-		if err := http.ListenAndServe(fmt.Sprintf(":%d", s.config.HttpPort), cors); err != nil && err != http.ErrServerClosed {
-			log.WithError(err).Errorf("failed to listen and server")
-		}
+		s.errs <- fmt.Errorf("failed to serve: %w", http.Serve(listener, cors))
 	}()
+
+	go func() {
+		s.errs <- fmt.Errorf("failed to serve monitoring: %w", http.Serve(monitoringListener, s.monitor.Handler()))
+	}()
+
+	return nil
 }
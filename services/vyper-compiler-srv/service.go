@@ -1,19 +1,80 @@
 package service
 
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/auth"
+	"github.com/openchainxyz/openchainxyz-monorepo/internal/monitoring"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
 type Config struct {
 	HttpPort int `def:"34887" env:"PORT"`
+	// MetricsPort serves /metrics, /healthz and /readyz, which aren't authenticated so shouldn't be exposed publicly
+	MetricsPort int `def:"34888" env:"METRICS_PORT"`
+
+	// ApiKeys are "name:role:token" entries accepted as bearer tokens
+	ApiKeys       []string `env:"API_KEYS"`
+	AnonymousRole string   `def:"read_only" env:"ANONYMOUS_ROLE"`
 }
 
 type Service struct {
 	config *Config
+
+	auth    *auth.Authenticator
+	monitor *monitoring.Monitor
+
+	compileDurations *prometheus.HistogramVec
+
+	// errs receives the reason the server stopped
+	errs chan error
 }
 
 func New(config *Config) (*Service, error) {
-	return &Service{config: config}, nil
+	anonymousRole := auth.Role(config.AnonymousRole)
+	if !anonymousRole.Valid() {
+		return nil, fmt.Errorf("invalid anonymous role: %s", config.AnonymousRole)
+	}
+
+	keys, err := auth.ParseStaticKeys(config.ApiKeys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse api keys: %w", err)
+	}
+
+	service := &Service{
+		config: config,
+		auth: auth.New(
+			auth.WithKeyStore(keys),
+			auth.WithAnonymousRole(anonymousRole),
+			auth.WithFailureHandler(func(w http.ResponseWriter, status int, err error) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(status)
+				json.NewEncoder(w).Encode(map[string]any{
+					"status":  "failed",
+					"message": err.Error(),
+				})
+			}),
+		),
+		monitor: monitoring.New("vyper-compiler-srv"),
+		compileDurations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "compile_duration_seconds",
+			Help:    "Time taken to compile contracts, by whether they compiled.",
+			Buckets: prometheus.ExponentialBuckets(0.1, 2, 10),
+		}, []string{"result"}),
+		errs: make(chan error),
+	}
+	service.monitor.MustRegister(service.compileDurations)
+
+	return service, nil
 }
 
 func (s *Service) Start() error {
-	go s.startServer()
+	return s.startServer()
+}
 
-	return nil
+// Wait blocks until one of the servers started by Start stops, returning why
+func (s *Service) Wait() error {
+	return <-s.errs
 }